@@ -0,0 +1,160 @@
+package ai
+
+import (
+	"math"
+
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+// board is a snapshot of the squares in a world, with how long until each of
+// them is clear of snakes. Squares are numbered along the rows, starting from
+// the bottom left.
+type board struct {
+	left   int
+	bottom int
+	width  int
+	height int
+	wrap   bool
+	// freeIn is how many steps it will be until each square is free, going
+	// by how far it is from the tail of the snake in it. It's 0 for squares
+	// that are free now.
+	freeIn []int
+}
+
+// newBoard takes a snapshot of world for the player driving the snake at
+// index me.
+func newBoard(world sim.WorldState, me int) *board {
+	b := &board{
+		left:   int(world.Edges.Left),
+		bottom: int(world.Edges.Bottom),
+		width:  int(world.Edges.Right - world.Edges.Left),
+		height: int(world.Edges.Top - world.Edges.Bottom),
+		wrap:   world.Wrap,
+	}
+	if b.width < 0 {
+		b.width = 0
+	}
+	if b.height < 0 {
+		b.height = 0
+	}
+	b.freeIn = make([]int, b.width*b.height)
+
+	for i, s := range world.Snakes {
+		if s.Invulnerable {
+			continue
+		}
+		// count the squares from the tail, so a square is free once the
+		// snake has moved on that many, after it's done growing.
+		steps := s.Growing
+		at := b.at
+		if i == me {
+			at = b.own(s)
+			// a Continuous snake that turns jumps its head forward to line
+			// up with a square, while its tail only moves on as far as
+			// usual, so it needs one more square between them.
+			if s.Movement != sim.Grid {
+				steps++
+			}
+		}
+		last := -1
+		for j := len(s.Locations) - 1; j >= 0; j-- {
+			c, ok := at(s.Locations[j])
+			if !ok {
+				continue
+			}
+			if c != last {
+				steps++
+				last = c
+			}
+			if steps > b.freeIn[c] {
+				b.freeIn[c] = steps
+			}
+		}
+	}
+	return b
+}
+
+// at returns the square l is in, if it's on the board.
+func (b *board) at(l sim.Location) (int, bool) {
+	return b.cell(int(math.Floor(l.X())), int(math.Floor(l.Y())))
+}
+
+// nearest returns the square l is nearest to, if it's on the board.
+func (b *board) nearest(l sim.Location) (int, bool) {
+	return b.cell(int(math.Round(l.X())), int(math.Round(l.Y())))
+}
+
+// own returns how to find the square a piece of the player's own snake s is
+// in. A Continuous snake turns at whichever square its head is nearest to,
+// and only runs into itself close to the rest of itself, so its pieces are
+// in the squares they're nearest to. Other snakes are run into wherever the
+// head is in the same square as them.
+func (b *board) own(s sim.SnakeState) func(l sim.Location) (int, bool) {
+	if s.Movement == sim.Grid {
+		return b.at
+	}
+	return b.nearest
+}
+
+// cell returns the square at x and y, wrapping them around if the board
+// wraps.
+func (b *board) cell(x int, y int) (int, bool) {
+	x -= b.left
+	y -= b.bottom
+	if b.wrap && b.width > 0 && b.height > 0 {
+		x = (x%b.width + b.width) % b.width
+		y = (y%b.height + b.height) % b.height
+	}
+	if x < 0 || x >= b.width || y < 0 || y >= b.height {
+		return 0, false
+	}
+	return y*b.width + x, true
+}
+
+// next returns the square one step from c in direction d, if there is one.
+func (b *board) next(c int, d sim.Direction) (int, bool) {
+	x := b.left + c%b.width
+	y := b.bottom + c/b.width
+	switch d {
+	case sim.Up:
+		y++
+	case sim.Down:
+		y--
+	case sim.Left:
+		x--
+	case sim.Right:
+		x++
+	}
+	return b.cell(x, y)
+}
+
+// open reports whether c will be free after the given number of steps.
+func (b *board) open(c int, steps int) bool {
+	return b.freeIn[c] <= steps
+}
+
+// distance is the fewest steps between two squares, ignoring anything in the
+// way.
+func (b *board) distance(from int, to int) int {
+	dx := abs(from%b.width - to%b.width)
+	dy := abs(from/b.width - to/b.width)
+	if b.wrap {
+		dx = min(dx, b.width-dx)
+		dy = min(dy, b.height-dy)
+	}
+	return dx + dy
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"math"
+
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+// Greedy is a sim.Controller that heads straight for the item, turning away
+// from anything it would run into next. It doesn't look any further ahead
+// than that, so it's easily trapped, which makes it something for the other
+// players to be measured against.
+type Greedy struct {
+	index int
+}
+
+// NewGreedy creates a Greedy for the snake at index in the world's snakes.
+func NewGreedy(index int) *Greedy {
+	return &Greedy{index: index}
+}
+
+func (g *Greedy) Intent(_ float64, world sim.WorldState) sim.Direction {
+	if g.index < 0 || g.index >= len(world.Snakes) {
+		return sim.None
+	}
+	me := world.Snakes[g.index]
+	if len(me.Locations) == 0 {
+		return sim.None
+	}
+
+	head := sim.Square(me.Locations[0])
+	best := sim.None
+	bestDistance := math.Inf(1)
+	for _, d := range []sim.Direction{me.Direction, sim.Up, sim.Down, sim.Left, sim.Right} {
+		if d == sim.None || d == me.Direction.Opposite() {
+			continue
+		}
+		next := head.Next(d)
+		if sim.Blocked(world, next) {
+			continue
+		}
+		if world.Wrap {
+			next = world.Edges.Wrap(next)
+		}
+		dx, dy := next.X()-world.Item.X(), next.Y()-world.Item.Y()
+		if world.Wrap {
+			dx, dy = world.Edges.Delta(next, world.Item)
+		}
+		if distance := math.Abs(dx) + math.Abs(dy); distance < bestDistance {
+			best = d
+			bestDistance = distance
+		}
+	}
+	if best == me.Direction {
+		return sim.None
+	}
+	return best
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+func TestGreedy(t *testing.T) {
+	edges := sim.Edges{Right: 10, Top: 10}
+	at := sim.NewLocation
+	tests := []struct {
+		name      string
+		me        []sim.Location
+		direction sim.Direction
+		item      sim.Location
+		wrap      bool
+		// another snake in the way, if there is one.
+		other []sim.Location
+		want  sim.Direction
+	}{
+		{name: "already heading for it", me: []sim.Location{at(5, 5)}, direction: sim.Right, item: at(8, 5), want: sim.None},
+		{name: "turns towards it", me: []sim.Location{at(5, 5)}, direction: sim.Right, item: at(5, 8), want: sim.Up},
+		{name: "starts off towards it", me: []sim.Location{at(5, 5)}, direction: sim.None, item: at(2, 5), want: sim.Left},
+		{
+			name: "never turns back", me: []sim.Location{at(5, 5), at(6, 5)}, direction: sim.Left, item: at(8, 7),
+			want: sim.Up,
+		},
+		{
+			name: "turns away from a wall", me: []sim.Location{at(9, 5), at(8, 5)}, direction: sim.Right, item: at(9, 0),
+			want: sim.Down,
+		},
+		{
+			name: "goes round a snake", me: []sim.Location{at(5, 5)}, direction: sim.Right, item: at(8, 5),
+			other: []sim.Location{at(6, 5), at(6, 4)}, want: sim.Up,
+		},
+		{
+			name: "goes the short way round", me: []sim.Location{at(1, 5)}, direction: sim.Up, item: at(8, 5), wrap: true,
+			want: sim.Left,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			world := sim.WorldState{
+				Snakes: []sim.SnakeState{{Locations: test.me, Direction: test.direction}},
+				Item:   test.item,
+				Edges:  edges,
+				Wrap:   test.wrap,
+			}
+			if test.other != nil {
+				world.Snakes = append(world.Snakes, sim.SnakeState{Locations: test.other})
+			}
+			if got := NewGreedy(0).Intent(0, world); got != test.want {
+				t.Errorf("Intent() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGreedyWithoutSnake(t *testing.T) {
+	world := sim.WorldState{
+		Snakes: []sim.SnakeState{{}},
+		Edges:  sim.Edges{Right: 10, Top: 10},
+	}
+	for _, index := range []int{-1, 0, 1} {
+		if got := NewGreedy(index).Intent(0, world); got != sim.None {
+			t.Errorf("Intent() for snake %d = %v, want None", index, got)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+// Package ai has computer players that drive snakes. They find their way to
+// the item with an A* search over the board, and look ahead with a flood fill
+// so they don't turn somewhere too small to get back out of.
+package ai
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+// directions are the ways a snake can go, in the order they're tried.
+var directions = []sim.Direction{sim.Up, sim.Right, sim.Down, sim.Left}
+
+// Player is a sim.Controller that steers one of the snakes in a world towards
+// the item, while keeping clear of walls and snakes, itself included.
+type Player struct {
+	index int
+	skill Skill
+
+	// when the player last thought about where to go.
+	thought    float64
+	hasThought bool
+	// the square the head was in when the player last turned, and how many
+	// times the snake had been reset then. It won't turn again until the
+	// snake has moved on from that square or come back after dying.
+	turnedAt int
+	resets   int
+	turned   bool
+	// when the player was last asked which way to go, and how long it was
+	// since the time before, which is taken to be how long it is until the
+	// snake next moves.
+	asked    float64
+	hasAsked bool
+	tick     float64
+}
+
+// NewPlayer creates a Player for the snake at index in the world's snakes.
+func NewPlayer(index int, skill Skill) *Player {
+	return &Player{
+		index: index,
+		skill: skill,
+	}
+}
+
+func (p *Player) Intent(t float64, world sim.WorldState) sim.Direction {
+	if p.index < 0 || p.index >= len(world.Snakes) || world.Over {
+		return sim.None
+	}
+	me := world.Snakes[p.index]
+	if len(me.Locations) == 0 {
+		return sim.None
+	}
+	if p.hasAsked {
+		p.tick = t - p.asked
+	}
+	p.asked = t
+	p.hasAsked = true
+	// wait for any turn already asked for to be made.
+	if me.Direction != me.Moving && me.Moving != sim.None {
+		return sim.None
+	}
+	if p.hasThought && t-p.thought < p.skill.Reaction {
+		return sim.None
+	}
+
+	b := newBoard(world, p.index)
+	head, ok := p.turningFrom(b, me)
+	if !ok {
+		return sim.None
+	}
+	if p.turned && head == p.turnedAt && me.Resets == p.resets {
+		return sim.None
+	}
+	p.thought = t
+	p.hasThought = true
+
+	d := p.choose(b, world, head, me.Direction)
+	if d == sim.None || d == me.Direction {
+		return sim.None
+	}
+	p.turnedAt = head
+	p.resets = me.Resets
+	p.turned = true
+	return d
+}
+
+// turningFrom returns the square the snake turns from if it's told to turn
+// now. A Grid snake turns from the square its head is in. A Continuous snake
+// turns from the square it's nearest to after its next move.
+func (p *Player) turningFrom(b *board, me sim.SnakeState) (int, bool) {
+	head := me.Locations[0]
+	if me.Movement == sim.Grid {
+		return b.at(head)
+	}
+	x, y := head.X(), head.Y()
+	step := me.Speed * p.tick
+	switch me.Moving {
+	case sim.Up:
+		y += step
+	case sim.Down:
+		y -= step
+	case sim.Right:
+		x += step
+	case sim.Left:
+		x -= step
+	}
+	return b.nearest(sim.NewLocation(x, y))
+}
+
+// option is a way the snake could go next.
+type option struct {
+	direction sim.Direction
+	square    int
+	// room is how many squares can be reached from square, up to as many as
+	// the snake needs.
+	room int
+	// risky is set if another snake's head could get to square first.
+	risky bool
+}
+
+// choose picks the way to go from head. It takes the first step towards the
+// item if that's safe, and otherwise goes wherever has the most room.
+func (p *Player) choose(b *board, world sim.WorldState, head int, heading sim.Direction) sim.Direction {
+	need := p.length(b, world)
+	heads := p.otherHeads(b, world)
+	// a Continuous snake's head may not have got to the square it turns
+	// from yet, but it's in the way all the same.
+	if b.freeIn[head] < need {
+		b.freeIn[head] = need
+	}
+
+	var options []option
+	for _, d := range directions {
+		if heading != sim.None && d == heading.Opposite() {
+			continue
+		}
+		c, ok := b.next(head, d)
+		if !ok || !b.open(c, 1) {
+			continue
+		}
+		o := option{direction: d, square: c, room: need}
+		if p.skill.Lookahead {
+			o.room = b.room(c, 1, need)
+			for _, h := range heads {
+				if b.distance(h, c) == 1 {
+					o.risky = true
+				}
+			}
+		}
+		options = append(options, o)
+	}
+	if len(options) == 0 {
+		return sim.None
+	}
+
+	best := options[0]
+	if item, ok := b.at(world.Item); ok {
+		towards := b.path(head, heading, item, p.skill.Depth)
+		for _, o := range options {
+			if o.direction == towards && o.room >= need && !o.risky {
+				return o.direction
+			}
+		}
+		for _, o := range options[1:] {
+			if better(o, best, b, item) {
+				best = o
+			}
+		}
+	}
+	return best.direction
+}
+
+// better reports whether o is a better way to go than best, when going
+// straight for the item isn't safe.
+func better(o option, best option, b *board, item int) bool {
+	if o.risky != best.risky {
+		return !o.risky
+	}
+	if o.room != best.room {
+		return o.room > best.room
+	}
+	return b.distance(o.square, item) < b.distance(best.square, item)
+}
+
+// length is how many squares the player's snake takes up.
+func (p *Player) length(b *board, world sim.WorldState) int {
+	squares := make(map[int]bool)
+	me := world.Snakes[p.index]
+	at := b.own(me)
+	for _, l := range me.Locations {
+		if c, ok := at(l); ok {
+			squares[c] = true
+		}
+	}
+	return len(squares)
+}
+
+// otherHeads returns the squares the heads of the other snakes that can be
+// run into are in.
+func (p *Player) otherHeads(b *board, world sim.WorldState) []int {
+	var heads []int
+	for i, s := range world.Snakes {
+		if i == p.index || s.Invulnerable || len(s.Locations) == 0 {
+			continue
+		}
+		if c, ok := b.at(s.Locations[0]); ok {
+			heads = append(heads, c)
+		}
+	}
+	return heads
+}
+
+// room counts the squares that can be reached from start, which is reached
+// after the given number of steps, stopping once it has found limit of them.
+func (b *board) room(start int, steps int, limit int) int {
+	seen := map[int]bool{start: true}
+	queue := []node{{square: start, steps: steps}}
+	for len(queue) > 0 && len(seen) < limit {
+		n := queue[0]
+		queue = queue[1:]
+		for _, d := range directions {
+			c, ok := b.next(n.square, d)
+			if !ok || seen[c] || !b.open(c, n.steps+1) {
+				continue
+			}
+			seen[c] = true
+			queue = append(queue, node{square: c, steps: n.steps + 1})
+		}
+	}
+	return len(seen)
+}
+
+// path searches for the shortest way from start to goal, without going back
+// the way the snake is heading, and returns the first step along it. If
+// depth is positive, it gives up on ways longer than that and heads for the
+// square it found closest to goal instead. It returns None if there's
+// nowhere to go.
+func (b *board) path(start int, heading sim.Direction, goal int, depth int) sim.Direction {
+	first := map[int]sim.Direction{}
+	steps := map[int]int{start: 0}
+	closest := -1
+	closestDistance := math.MaxInt
+
+	open := &nodes{}
+	heap.Push(open, node{square: start})
+	for open.Len() > 0 {
+		n := heap.Pop(open).(node)
+		if n.steps > steps[n.square] {
+			continue
+		}
+		if n.square == goal {
+			return first[goal]
+		}
+		if dist := b.distance(n.square, goal); n.square != start && dist < closestDistance {
+			closest = n.square
+			closestDistance = dist
+		}
+		if depth > 0 && n.steps >= depth {
+			continue
+		}
+
+		for _, d := range directions {
+			if n.square == start && heading != sim.None && d == heading.Opposite() {
+				continue
+			}
+			c, ok := b.next(n.square, d)
+			if !ok || !b.open(c, n.steps+1) {
+				continue
+			}
+			if s, seen := steps[c]; seen && s <= n.steps+1 {
+				continue
+			}
+			steps[c] = n.steps + 1
+			first[c] = first[n.square]
+			if n.square == start {
+				first[c] = d
+			}
+			heap.Push(open, node{
+				square:   c,
+				steps:    n.steps + 1,
+				estimate: n.steps + 1 + b.distance(c, goal),
+			})
+		}
+	}
+	if closest < 0 {
+		return sim.None
+	}
+	return first[closest]
+}
+
+// node is a square found by a search, and how many steps it took to get to.
+type node struct {
+	square int
+	steps  int
+	// estimate is the steps it took plus the fewest it could take from here
+	// to the goal.
+	estimate int
+}
+
+// nodes is a heap of the nodes still to be searched, with the lowest
+// estimate first.
+type nodes []node
+
+func (n nodes) Len() int           { return len(n) }
+func (n nodes) Less(i, j int) bool { return n[i].estimate < n[j].estimate }
+func (n nodes) Swap(i, j int)      { n[i], n[j] = n[j], n[i] }
+
+func (n *nodes) Push(x any) {
+	*n = append(*n, x.(node))
+}
+
+func (n *nodes) Pop() any {
+	old := *n
+	x := old[len(old)-1]
+	*n = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,113 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/kristinaspring/snake-go/events"
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+const deltaT = 1.0 / 60
+
+var movements = []struct {
+	name     string
+	movement sim.Movement
+}{
+	{"continuous", sim.Continuous},
+	{"grid", sim.Grid},
+}
+
+// snakeConfig is how the snakes in snake.yaml are set up, with one life.
+func snakeConfig(movement sim.Movement) sim.SnakeConfig {
+	return sim.SnakeConfig{
+		Movement:       movement,
+		SquareSize:     10,
+		PixelsPerSec:   10,
+		Difficulty:     sim.Normal,
+		StartingFrames: 15,
+		FramesToGrow:   5,
+		Threshold:      5,
+		TurnBuffer:     3,
+		Lives:          1,
+	}
+}
+
+// run steps world until it's over or seconds of game time have gone by.
+func run(world *sim.World, seconds float64) {
+	for t := 0.0; t < seconds && !world.Over(); t += deltaT {
+		world.Steer(t)
+		world.Step(t, deltaT)
+	}
+}
+
+// deaths collects every SnakeDied published to bus.
+func deaths(bus *events.Bus) *[]events.SnakeDied {
+	var died []events.SnakeDied
+	events.Subscribe(bus, func(e events.SnakeDied) {
+		died = append(died, e)
+	})
+	return &died
+}
+
+func TestPlayerAvoidsWalls(t *testing.T) {
+	edges := sim.Edges{Right: 10, Top: 10}
+	tests := []struct {
+		name      string
+		start     sim.Location
+		direction sim.Direction
+	}{
+		{name: "right", start: sim.NewLocation(8, 5), direction: sim.Right},
+		{name: "left", start: sim.NewLocation(1, 5), direction: sim.Left},
+		{name: "up", start: sim.NewLocation(5, 8), direction: sim.Up},
+		{name: "down", start: sim.NewLocation(5, 1), direction: sim.Down},
+	}
+
+	for _, m := range movements {
+		for _, test := range tests {
+			t.Run(m.name+" "+test.name, func(t *testing.T) {
+				bus := events.NewBus()
+				died := deaths(bus)
+				occupancy := sim.NewOccupancy(edges)
+				item := sim.NewSingleTracker(edges, bus, occupancy)
+				item.Seed(1)
+				c := snakeConfig(m.movement)
+				c.Events = bus
+				c.Edges = edges
+				c.Occupancy = occupancy
+				c.StartingPosition = test.start
+				c.StartingFrames = 3
+				snake := sim.NewSnake(item, c)
+				world := sim.NewWorld(bus, item, snake)
+				world.RelocateItem()
+				world.SetController(0, NewPlayer(0, Hard))
+
+				// send the snake straight at the wall to begin with.
+				snake.SetDirection(test.direction)
+				run(world, 10)
+
+				for _, d := range *died {
+					t.Errorf("snake died of %v with a score of %d", d.Cause, d.Score)
+				}
+			})
+		}
+	}
+}
+
+func TestPlayerAvoidsItself(t *testing.T) {
+	for _, m := range movements {
+		for seed := int64(0); seed < 5; seed++ {
+			bus := events.NewBus()
+			died := deaths(bus)
+			world := sim.NewGame(bus, sim.Edges{Right: 40, Top: 40}, snakeConfig(m.movement), sim.BothDie, 1, seed)
+			world.SetController(0, NewPlayer(0, Hard))
+			run(world, 60)
+
+			for _, d := range *died {
+				t.Errorf("%s, seed %d: snake died of %v with a score of %d", m.name, seed, d.Cause, d.Score)
+			}
+			if score := world.State().Snakes[0].Score; score < 10 {
+				t.Errorf("%s, seed %d: score = %d, want at least 10", m.name, seed, score)
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package ai
+
+import "strings"
+
+// Skill decides how good an AI player is.
+type Skill struct {
+	// Depth is how many squares ahead the player searches for a way to the
+	// item. It searches the whole board if it's not positive.
+	Depth int
+	// Reaction is how many seconds it takes the player to rethink where
+	// it's going.
+	Reaction float64
+	// Lookahead is set if the player makes sure there's room for it
+	// wherever it goes, and keeps clear of other snakes' heads.
+	Lookahead bool
+}
+
+var (
+	Easy   = Skill{Depth: 6, Reaction: 0.15}
+	Normal = Skill{Depth: 20, Reaction: 0.05, Lookahead: true}
+	Hard   = Skill{Lookahead: true}
+)
+
+func GetSkill(s string) Skill {
+	ls := strings.ToLower(s)
+	switch ls {
+	case "easy":
+		return Easy
+	case "hard":
+		return Hard
+	default:
+		return Normal
+	}
+}
@@ -0,0 +1,274 @@
+// Package bot lets snakes be driven by programs written in any language. A
+// bot reads the state of the world from its stdin as one line of JSON every
+// tick, and writes back one line of JSON naming the tick it's answering and
+// the way it wants to go. The game doesn't wait for it: each tick the snake
+// turns the way the latest answer that's come in says, and a bot that's
+// fallen behind can skip straight to answering the latest tick. A bot that
+// leaves a tick unanswered for longer than its time limit, answers with
+// anything other than a Reply, or stops running, is disqualified.
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+// DefaultTimeLimit is how long a bot has to answer each tick, unless it's
+// given a limit of its own.
+const DefaultTimeLimit = 50 * time.Millisecond
+
+// Bot is a sim.Controller that drives a snake by asking a bot which way to
+// go. Once the bot is disqualified, it forfeits the game.
+type Bot struct {
+	index int
+	limit time.Duration
+	// the latest state to send, which the writer takes. A state that
+	// hasn't been taken by the time the next one is ready is skipped.
+	states chan []byte
+	// signalled each time an answer comes in.
+	answers chan struct{}
+	done    chan struct{}
+	// stops the bot, if it's running as a process.
+	stop func()
+
+	lock sync.Mutex
+	tick int
+	// answered is the latest tick the bot has answered, and sent holds when
+	// every tick after it was sent.
+	answered int
+	sent     []time.Time
+	// the direction the latest answer asked for, until it's been taken.
+	direction sim.Direction
+	forfeited error
+	closed    bool
+}
+
+// New creates a Bot for the snake at index in the world's snakes, which
+// sends states to w and reads replies from r. A limit that isn't positive is
+// DefaultTimeLimit.
+func New(index int, r io.Reader, w io.Writer, limit time.Duration) *Bot {
+	if limit <= 0 {
+		limit = DefaultTimeLimit
+	}
+	b := &Bot{
+		index:   index,
+		limit:   limit,
+		states:  make(chan []byte, 1),
+		answers: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go b.read(r)
+	go b.write(w)
+	return b
+}
+
+// Start runs command as the bot for the snake at index. Anything it writes to
+// its stderr is passed on to stderr.
+func Start(index int, command []string, limit time.Duration, stderr io.Writer) (*Bot, error) {
+	if len(command) == 0 {
+		return nil, errors.New("no command to run")
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stderr = stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	b := New(index, stdout, stdin, limit)
+	b.stop = func() {
+		stdin.Close()
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+	return b, nil
+}
+
+// read takes in every reply read from r, until r runs out or the bot is
+// disqualified.
+func (b *Bot) read(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+	for scanner.Scan() {
+		var rep Reply
+		err := json.Unmarshal(scanner.Bytes(), &rep)
+		if err != nil {
+			err = fmt.Errorf("reading a reply: %w", err)
+		}
+		if !b.answer(rep, err) {
+			return
+		}
+	}
+	err := scanner.Err()
+	if err == nil {
+		err = errors.New("stopped running")
+	}
+	b.answer(Reply{}, err)
+}
+
+// answer takes in rep, or disqualifies the bot for err, and reports whether
+// the bot can still answer.
+func (b *Bot) answer(rep Reply, err error) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.forfeited != nil || b.closed {
+		return false
+	}
+
+	d := sim.GetDirection(rep.Direction)
+	switch {
+	case err != nil:
+	case rep.Tick <= 0:
+		err = errors.New("reply without a tick")
+	case rep.Tick > b.tick:
+		err = fmt.Errorf("reply to tick %d, which hasn't been sent", rep.Tick)
+	case d == sim.None && rep.Direction != "" && strings.ToLower(rep.Direction) != "none":
+		err = fmt.Errorf("tick %d: unknown direction %q", rep.Tick, rep.Direction)
+	}
+	if err != nil {
+		b.forfeit(err)
+		return false
+	}
+	// a reply to a tick that's been overtaken by a later answer is stale.
+	if rep.Tick <= b.answered {
+		return true
+	}
+
+	b.sent = b.sent[rep.Tick-b.answered:]
+	b.answered = rep.Tick
+	b.direction = d
+	select {
+	case b.answers <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// write sends each state to w as it's ready, until the bot is closed.
+func (b *Bot) write(w io.Writer) {
+	for {
+		select {
+		case line := <-b.states:
+			_, err := w.Write(line)
+			if err != nil {
+				b.lock.Lock()
+				if b.forfeited == nil && !b.closed {
+					b.forfeit(fmt.Errorf("sending a tick: %w", err))
+				}
+				b.lock.Unlock()
+				return
+			}
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// Intent sends the bot the world and turns the way the latest answer that's
+// come in says, without waiting for the bot to answer this tick.
+func (b *Bot) Intent(_ float64, world sim.WorldState) sim.Direction {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.forfeited != nil || b.closed {
+		return sim.None
+	}
+	if len(b.sent) > 0 && time.Since(b.sent[0]) > b.limit {
+		b.forfeit(fmt.Errorf("no answer to tick %d within %v", b.answered+1, b.limit))
+		return sim.None
+	}
+	d := b.direction
+	b.direction = sim.None
+
+	b.tick++
+	line, err := json.Marshal(newState(b.tick, b.index, world))
+	if err != nil {
+		b.forfeit(err)
+		return sim.None
+	}
+	b.sent = append(b.sent, time.Now())
+	// only the writer takes states, so once a state it hasn't got to yet is
+	// skipped there's room for this one.
+	select {
+	case <-b.states:
+	default:
+	}
+	b.states <- append(line, '\n')
+	return d
+}
+
+// Wait waits until the bot has answered the latest tick it's been sent, or
+// has run out of time to. Something that steps the game faster than in real
+// time, like a tournament, waits for its bots between ticks so they get the
+// same chance to answer each one as they would in a game played in real
+// time.
+func (b *Bot) Wait() {
+	for {
+		b.lock.Lock()
+		if b.forfeited != nil || b.closed || len(b.sent) == 0 {
+			b.lock.Unlock()
+			return
+		}
+		left := b.limit - time.Since(b.sent[0])
+		b.lock.Unlock()
+		if left <= 0 {
+			return
+		}
+
+		timer := time.NewTimer(left)
+		select {
+		case <-b.answers:
+		case <-timer.C:
+		case <-b.done:
+		}
+		timer.Stop()
+	}
+}
+
+// forfeit disqualifies the bot for err and stops it. It has to be called with
+// the lock held.
+func (b *Bot) forfeit(err error) {
+	b.forfeited = err
+	b.close()
+}
+
+// Forfeited returns why the bot was disqualified, or nil if it hasn't been.
+func (b *Bot) Forfeited() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.forfeited
+}
+
+// Close stops the bot, if it's still running.
+func (b *Bot) Close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.close()
+}
+
+func (b *Bot) close() {
+	if b.closed {
+		return
+	}
+	b.closed = true
+	close(b.done)
+	if b.stop != nil {
+		b.stop()
+	}
+}
@@ -0,0 +1,199 @@
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+// fake stands in for a bot's process, with pipes for what it's sent and
+// what it answers.
+type fake struct {
+	bot     *Bot
+	states  *bufio.Scanner
+	replies *io.PipeWriter
+}
+
+func newFake(t *testing.T, limit time.Duration) *fake {
+	t.Helper()
+	stateR, stateW := io.Pipe()
+	replyR, replyW := io.Pipe()
+	f := &fake{
+		bot:     New(1, replyR, stateW, limit),
+		states:  bufio.NewScanner(stateR),
+		replies: replyW,
+	}
+	t.Cleanup(func() {
+		f.bot.Close()
+		replyW.Close()
+		stateR.Close()
+	})
+	return f
+}
+
+// receive reads the next state the bot was sent.
+func (f *fake) receive(t *testing.T) State {
+	t.Helper()
+	if !f.states.Scan() {
+		t.Fatalf("no state sent: %v", f.states.Err())
+	}
+	var s State
+	if err := json.Unmarshal(f.states.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// reply answers with line, and waits for the bot to take it in.
+func (f *fake) reply(t *testing.T, line string) {
+	t.Helper()
+	if _, err := io.WriteString(f.replies, line+"\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.bot.Wait()
+}
+
+var world = sim.WorldState{
+	Snakes: []sim.SnakeState{{}, {Locations: []sim.Location{sim.NewLocation(2, 3)}, Direction: sim.Up, Lives: 1}},
+	Item:   sim.NewLocation(5, 5),
+	Edges:  sim.Edges{Right: 10, Top: 10},
+}
+
+func TestBotAnswers(t *testing.T) {
+	f := newFake(t, time.Second)
+
+	if d := f.bot.Intent(0, world); d != sim.None {
+		t.Errorf("Intent() before any answer = %v, want none", d)
+	}
+	s := f.receive(t)
+	if s.Tick != 1 || s.You != 1 || len(s.Snakes) != 2 || s.Snakes[1].Body[0] != (Point{2, 3}) || s.Item != (Point{5, 5}) {
+		t.Errorf("sent %+v", s)
+	}
+	f.reply(t, `{"tick": 1, "direction": "left"}`)
+
+	// an answer is taken once, on the tick after it comes in.
+	if d := f.bot.Intent(0, world); d != sim.Left {
+		t.Errorf("Intent() = %v, want left", d)
+	}
+	if s := f.receive(t); s.Tick != 2 {
+		t.Errorf("sent tick %d, want 2", s.Tick)
+	}
+	if d := f.bot.Intent(0, world); d != sim.None {
+		t.Errorf("Intent() with no new answer = %v, want none", d)
+	}
+	if err := f.bot.Forfeited(); err != nil {
+		t.Errorf("Forfeited() = %v", err)
+	}
+}
+
+func TestBotDoesntWait(t *testing.T) {
+	// a bot that never reads what it's sent or answers doesn't hold up the
+	// game before its time runs out.
+	f := newFake(t, time.Second)
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if d := f.bot.Intent(0, world); d != sim.None {
+			t.Errorf("Intent() = %v, want none", d)
+		}
+	}
+	if took := time.Since(start); took > 100*time.Millisecond {
+		t.Errorf("10 ticks took %v", took)
+	}
+	if err := f.bot.Forfeited(); err != nil {
+		t.Errorf("Forfeited() = %v", err)
+	}
+}
+
+func TestBotStaleReplies(t *testing.T) {
+	f := newFake(t, time.Second)
+	f.bot.Intent(0, world)
+	f.receive(t)
+	f.bot.Intent(0, world)
+	f.receive(t)
+
+	// a bot that's behind can answer the latest tick, and anything it
+	// says about older ones after that is skipped.
+	f.reply(t, `{"tick": 2, "direction": "up"}`)
+	f.reply(t, `{"tick": 1, "direction": "left"}`)
+	if d := f.bot.Intent(0, world); d != sim.Up {
+		t.Errorf("Intent() = %v, want up", d)
+	}
+	f.receive(t)
+	f.reply(t, `{"tick": 3, "direction": "none"}`)
+	if d := f.bot.Intent(0, world); d != sim.None {
+		t.Errorf("Intent() = %v, want none", d)
+	}
+	if err := f.bot.Forfeited(); err != nil {
+		t.Errorf("Forfeited() = %v", err)
+	}
+}
+
+func TestBotForfeits(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  string
+	}{
+		{name: "malformed", reply: `{"tick": 1, "direction": `, want: "reading a reply"},
+		{name: "unknown direction", reply: `{"tick": 1, "direction": "sideways"}`, want: "unknown direction"},
+		{name: "no tick", reply: `{"direction": "up"}`, want: "without a tick"},
+		{name: "tick not sent", reply: `{"tick": 2, "direction": "up"}`, want: "hasn't been sent"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			f := newFake(t, time.Second)
+			f.bot.Intent(0, world)
+			f.receive(t)
+			f.reply(t, test.reply)
+
+			err := f.bot.Forfeited()
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("Forfeited() = %v, want an error about %q", err, test.want)
+			}
+			if d := f.bot.Intent(0, world); d != sim.None {
+				t.Errorf("Intent() after forfeiting = %v, want none", d)
+			}
+		})
+	}
+}
+
+func TestBotStopsRunning(t *testing.T) {
+	f := newFake(t, time.Second)
+	f.bot.Intent(0, world)
+	f.replies.Close()
+	f.bot.Wait()
+
+	if err := f.bot.Forfeited(); err == nil || !strings.Contains(err.Error(), "stopped running") {
+		t.Errorf("Forfeited() = %v, want an error about it stopping", err)
+	}
+}
+
+func TestBotTimesOut(t *testing.T) {
+	const limit = 20 * time.Millisecond
+	f := newFake(t, limit)
+	f.bot.Intent(0, world)
+	f.receive(t)
+
+	start := time.Now()
+	f.bot.Wait()
+	if waited := time.Since(start); waited < limit/2 {
+		t.Errorf("Wait() returned after %v, before the time limit", waited)
+	}
+	if err := f.bot.Forfeited(); err != nil {
+		t.Fatalf("Forfeited() = %v before the next tick", err)
+	}
+
+	// running out of time is found on the next tick.
+	if d := f.bot.Intent(0, world); d != sim.None {
+		t.Errorf("Intent() = %v, want none", d)
+	}
+	if err := f.bot.Forfeited(); err == nil || !strings.Contains(err.Error(), "no answer to tick 1") {
+		t.Errorf("Forfeited() = %v, want an error about tick 1", err)
+	}
+}
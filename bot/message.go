@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+// State is what a bot is sent every tick, as one line of JSON.
+type State struct {
+	// Tick counts the ticks the bot has been sent, starting from 1.
+	Tick int `json:"tick"`
+	// You is the index of the bot's own snake in Snakes.
+	You    int     `json:"you"`
+	Edges  Edges   `json:"edges"`
+	Wrap   bool    `json:"wrap"`
+	Item   Point   `json:"item"`
+	Snakes []Snake `json:"snakes"`
+}
+
+// Edges are the edges of the board. Squares go from Left up to but not
+// including Right, and from Bottom up to but not including Top.
+type Edges struct {
+	Left   float64 `json:"left"`
+	Right  float64 `json:"right"`
+	Top    float64 `json:"top"`
+	Bottom float64 `json:"bottom"`
+}
+
+// Point is a spot on the board, measured in squares.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Snake is one of the snakes on the board.
+type Snake struct {
+	// Body goes from the head of the snake to its tail. It's empty while the
+	// snake is off the board.
+	Body         []Point `json:"body"`
+	Direction    string  `json:"direction"`
+	Score        int     `json:"score"`
+	Lives        int     `json:"lives"`
+	Invulnerable bool    `json:"invulnerable"`
+}
+
+// Reply is what a bot answers a tick with, as one line of JSON. Tick is the
+// tick of the state it's answering, and a reply without one disqualifies the
+// bot. A reply to a tick older than one that's already been answered is
+// skipped. A direction of "" or "none" carries on the way the snake is going.
+type Reply struct {
+	Tick      int    `json:"tick"`
+	Direction string `json:"direction"`
+}
+
+// newState turns a snapshot of the world into the state sent to the bot
+// driving the snake at index you.
+func newState(tick int, you int, world sim.WorldState) State {
+	s := State{
+		Tick: tick,
+		You:  you,
+		Edges: Edges{
+			Left:   world.Edges.Left,
+			Right:  world.Edges.Right,
+			Top:    world.Edges.Top,
+			Bottom: world.Edges.Bottom,
+		},
+		Wrap:   world.Wrap,
+		Item:   Point{X: world.Item.X(), Y: world.Item.Y()},
+		Snakes: make([]Snake, len(world.Snakes)),
+	}
+	for i, snake := range world.Snakes {
+		body := make([]Point, len(snake.Locations))
+		for j, l := range snake.Locations {
+			body[j] = Point{X: l.X(), Y: l.Y()}
+		}
+		s.Snakes[i] = Snake{
+			Body:         body,
+			Direction:    snake.Direction.String(),
+			Score:        snake.Score,
+			Lives:        snake.Lives,
+			Invulnerable: snake.Invulnerable,
+		}
+	}
+	return s
+}
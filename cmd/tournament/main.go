@@ -0,0 +1,226 @@
+// Command tournament plays the entrants in tournament.yaml against each other
+// with no window and writes out how they did.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kristinaspring/snake-go/ai"
+	"github.com/kristinaspring/snake-go/bot"
+	"github.com/kristinaspring/snake-go/sim"
+	"github.com/kristinaspring/snake-go/tournament"
+	"github.com/spf13/viper"
+)
+
+type Config struct {
+	Tournament TournamentConfig
+	Board      BoardConfig
+	Snake      SnakeConfig
+	Entrants   []EntrantConfig
+	Output     OutputConfig
+}
+
+type TournamentConfig struct {
+	// Games is how many games each pair of entrants plays.
+	Games int
+	Seed  int64
+	// TickRate is how many times a second, in game time, the snakes move.
+	TickRate int
+	// MaxSeconds is how long a game can go on for, in game time, before
+	// it's decided on score.
+	MaxSeconds float64
+}
+
+type BoardConfig struct {
+	SquareSize     float64
+	NumSquaresWide float64
+	NumSquaresHigh float64
+	Wrap           bool
+	HeadOn         string
+}
+
+type SnakeConfig struct {
+	Speed          float64
+	Difficulty     string
+	Movement       string
+	StartingFrames int
+	FramesToGrow   int
+	Threshold      float64
+	TurnBuffer     int
+	Lives          int
+	RespawnDelay   float64
+	Invulnerable   float64
+}
+
+// EntrantConfig describes something that plays in the tournament: an ai
+// with a skill, the greedy sim controller, or a bot run with a command.
+type EntrantConfig struct {
+	Name       string
+	Controller string
+	Skill      string
+	Command    []string
+	TimeLimit  float64
+}
+
+// OutputConfig is where to write the results. Nothing is written for any
+// that are empty.
+type OutputConfig struct {
+	JSON     string
+	CSV      string
+	TableCSV string
+}
+
+func main() {
+	configPath := flag.String("config", "tournament.yaml", "the tournament to run")
+	games := flag.Int("games", 0, "how many games each pair plays, instead of the config's")
+	seed := flag.Int64("seed", 0, "the seed for the first game, instead of the config's")
+	flag.Parse()
+
+	v := viper.New()
+	v.SetConfigFile(*configPath)
+	err := v.ReadInConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read in viper config: %v\n", err.Error())
+		os.Exit(1)
+	}
+	config := new(Config)
+	err = v.Unmarshal(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to unmarshal config: %v\n", err.Error())
+		os.Exit(1)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "games":
+			config.Tournament.Games = *games
+		case "seed":
+			config.Tournament.Seed = *seed
+		}
+	})
+
+	entrants := make([]tournament.Entrant, len(config.Entrants))
+	for i, e := range config.Entrants {
+		entrants[i], err = newEntrant(i, e)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bad entrant %s: %v\n", entrants[i].Name, err)
+			os.Exit(1)
+		}
+	}
+	if len(entrants) < 2 {
+		fmt.Fprintln(os.Stderr, "a tournament needs at least two entrants")
+		os.Exit(1)
+	}
+
+	results, err := tournament.Run(newSetup(config), entrants)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tournament failed: %v\n", err.Error())
+		os.Exit(1)
+	}
+
+	printStandings(results)
+	for _, out := range []struct {
+		path  string
+		write func(f *os.File) error
+	}{
+		{config.Output.JSON, func(f *os.File) error { return results.WriteJSON(f) }},
+		{config.Output.CSV, func(f *os.File) error { return results.WriteCSV(f) }},
+		{config.Output.TableCSV, func(f *os.File) error { return results.WriteTableCSV(f) }},
+	} {
+		if out.path == "" {
+			continue
+		}
+		err = writeFile(out.path, out.write)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write results: %v\n", err.Error())
+			os.Exit(1)
+		}
+	}
+}
+
+func newSetup(config *Config) tournament.Setup {
+	setup := tournament.Setup{
+		Edges: sim.Edges{
+			Left:   0,
+			Right:  config.Board.NumSquaresWide,
+			Bottom: 0,
+			Top:    config.Board.NumSquaresHigh,
+		},
+		Snake: sim.SnakeConfig{
+			Wrap:           config.Board.Wrap,
+			Movement:       sim.GetMovement(config.Snake.Movement),
+			SquareSize:     config.Board.SquareSize,
+			PixelsPerSec:   config.Snake.Speed,
+			Difficulty:     sim.GetDifficulty(config.Snake.Difficulty),
+			StartingFrames: config.Snake.StartingFrames,
+			FramesToGrow:   config.Snake.FramesToGrow,
+			Threshold:      config.Snake.Threshold,
+			TurnBuffer:     config.Snake.TurnBuffer,
+			Lives:          config.Snake.Lives,
+			RespawnDelay:   config.Snake.RespawnDelay,
+			Invulnerable:   config.Snake.Invulnerable,
+		},
+		Rule:    sim.GetRule(config.Board.HeadOn),
+		MaxTime: time.Duration(config.Tournament.MaxSeconds * float64(time.Second)),
+		Games:   config.Tournament.Games,
+		Seed:    config.Tournament.Seed,
+	}
+	if config.Tournament.TickRate > 0 {
+		setup.TickRate = time.Second / time.Duration(config.Tournament.TickRate)
+	}
+	return setup
+}
+
+// newEntrant makes entrant index, as e configures it.
+func newEntrant(index int, e EntrantConfig) (tournament.Entrant, error) {
+	entrant := tournament.Entrant{Name: e.Name}
+	if entrant.Name == "" {
+		entrant.Name = fmt.Sprintf("entrant %d", index+1)
+	}
+
+	switch strings.ToLower(e.Controller) {
+	case "", "ai":
+		skill := ai.GetSkill(e.Skill)
+		entrant.New = func(index int) (sim.Controller, error) {
+			return ai.NewPlayer(index, skill), nil
+		}
+	case "greedy":
+		entrant.New = func(index int) (sim.Controller, error) {
+			return ai.NewGreedy(index), nil
+		}
+	case "bot":
+		limit := time.Duration(e.TimeLimit * float64(time.Second))
+		entrant.New = func(index int) (sim.Controller, error) {
+			return bot.Start(index, e.Command, limit, os.Stderr)
+		}
+	default:
+		return entrant, fmt.Errorf("unknown controller %q", e.Controller)
+	}
+	return entrant, nil
+}
+
+func printStandings(results *tournament.Results) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "name\tgames\twins\tlosses\tdraws\tavg score\trating\t")
+	for _, s := range results.Ranked() {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%.2f\t%.1f\t\n", s.Name, s.Games, s.Wins, s.Losses, s.Draws, s.AverageScore, s.Rating)
+	}
+	w.Flush()
+}
+
+func writeFile(path string, write func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
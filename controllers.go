@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/kristinaspring/snake-go/ai"
+	"github.com/kristinaspring/snake-go/bot"
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+// stickDeadZone is how far a gamepad's stick has to be pushed before it
+// counts as pointing anywhere.
+const stickDeadZone = 0.5
+
+// gamepad turns a snake with a gamepad's left stick.
+type gamepad struct {
+	joystick pixelgl.Joystick
+	queue    *sim.Queue
+	// the way the stick was pointing when it was last sampled.
+	last sim.Direction
+}
+
+// sample pushes the way the stick is pointing each time it starts pointing
+// somewhere new. It has to be called from the main thread, after the window
+// has been updated.
+func (p *gamepad) sample(win *pixelgl.Window) {
+	x := win.JoystickAxis(p.joystick, 0)
+	y := win.JoystickAxis(p.joystick, 1)
+
+	d := sim.None
+	switch {
+	case math.Abs(x) < stickDeadZone && math.Abs(y) < stickDeadZone:
+	case math.Abs(x) > math.Abs(y) && x > 0:
+		d = sim.Right
+	case math.Abs(x) > math.Abs(y):
+		d = sim.Left
+	// sticks report up as negative.
+	case y > 0:
+		d = sim.Down
+	default:
+		d = sim.Up
+	}
+	if d != p.last {
+		p.queue.Push(d)
+		p.last = d
+	}
+}
+
+// recording is a player's recorded intents, and where to write them.
+type recording struct {
+	path      string
+	recording *sim.Recording
+}
+
+// addController makes what drives player index's snake, as p configures it.
+// Keyboard and gamepad players are fed from the main thread by sampleInput.
+// A player's intents are recorded too if they've been asked for.
+func (g *Game) addController(index int, p PlayerConfig) (sim.Controller, error) {
+	var c sim.Controller
+	switch strings.ToLower(p.Controller) {
+	case "", "keyboard":
+		keys, err := playerBindings(index, p.Keys)
+		if err != nil {
+			return nil, err
+		}
+		q := sim.NewQueue(g.turnBuffer)
+		g.keys[index] = keys
+		g.keyboards[index] = q
+		c = q
+	case "gamepad":
+		js := pixelgl.Joystick1 + pixelgl.Joystick(p.Gamepad-1)
+		if p.Gamepad < 1 || js > pixelgl.JoystickLast {
+			return nil, fmt.Errorf("no gamepad %d", p.Gamepad)
+		}
+		q := sim.NewQueue(g.turnBuffer)
+		g.gamepads = append(g.gamepads, &gamepad{joystick: js, queue: q})
+		c = q
+	case "ai":
+		c = ai.NewPlayer(index, ai.GetSkill(p.Skill))
+	case "bot":
+		b, err := bot.Start(index, p.Command, time.Duration(p.TimeLimit*float64(time.Second)), os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		g.bots = append(g.bots, b)
+		c = b
+	case "network":
+		q := sim.NewQueue(g.turnBuffer)
+		err := listenForPlayer(p.Address, q)
+		if err != nil {
+			return nil, err
+		}
+		c = q
+	case "replay":
+		c = g.replays[index]
+	default:
+		return nil, fmt.Errorf("unknown controller %q", p.Controller)
+	}
+
+	if p.Record != "" {
+		r := sim.Record(c, g.seed)
+		g.recordings = append(g.recordings, recording{path: p.Record, recording: r})
+		c = r
+	}
+	return c, nil
+}
+
+// loadReplays reads the recording every replay player plays back, before
+// the game is set up so it can be set up with the same seed. Players who
+// aren't replays have no replay.
+func loadReplays(players []PlayerConfig) ([]*sim.Replay, error) {
+	replays := make([]*sim.Replay, len(players))
+	for i, p := range players {
+		if strings.ToLower(p.Controller) != "replay" {
+			continue
+		}
+		f, err := os.Open(p.Replay)
+		if err != nil {
+			return nil, err
+		}
+		replays[i], err = sim.NewReplay(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Replay, err)
+		}
+	}
+	return replays, nil
+}
+
+// itemSeed picks the seed items are placed with: the one replays were
+// recorded with if there are any, otherwise seed, or the time if seed is 0.
+func itemSeed(seed int64, replays []*sim.Replay) (int64, error) {
+	recorded := false
+	for _, r := range replays {
+		if r == nil {
+			continue
+		}
+		s, ok := r.Seed()
+		if !ok {
+			continue
+		}
+		if recorded && s != seed {
+			return 0, fmt.Errorf("replays were recorded with different seeds")
+		}
+		seed = s
+		recorded = true
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return seed, nil
+}
+
+// listenForPlayer drives q with the directions sent by whoever connects to
+// address, one name to a line. Only one connection is read from at a time,
+// and another can be made once it closes.
+func listenForPlayer(address string, q *sim.Queue) error {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "stopped listening on %s: %v\n", address, err.Error())
+				return
+			}
+			err = sim.ReadDirections(conn, q)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "lost player on %s: %v\n", address, err.Error())
+			}
+			conn.Close()
+		}
+	}()
+	return nil
+}
+
+// writeRecordings writes out every player's recorded intents.
+func writeRecordings(recordings []recording) error {
+	for _, r := range recordings {
+		f, err := os.Create(r.path)
+		if err != nil {
+			return err
+		}
+		_, err = r.recording.WriteTo(f)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		err = f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
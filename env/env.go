@@ -0,0 +1,202 @@
+// Package env wraps the game up for training agents to play it, in the style
+// of a Gym environment: Reset starts an episode and Step moves it on one
+// action at a time, saying how much reward the agent earned. The agent always
+// drives the first snake, and any opponents drive the rest.
+//
+// Every Env has its own world and its own random numbers, so any number of
+// them can be run at once, each in its own goroutine. A single Env isn't safe
+// to use from more than one goroutine at a time.
+package env
+
+import (
+	"github.com/kristinaspring/snake-go/events"
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+// Actions is how many actions there are. Actions are the sim.Directions from
+// sim.None, which carries on the way the snake is going, up to sim.Left.
+const Actions = int(sim.Left) + 1
+
+// Config describes the episodes an Env plays.
+type Config struct {
+	Edges sim.Edges
+	// Snake is how every snake is set up, as sim.NewGame sets them up,
+	// except that they always move a square at a time, as sim.Grid snakes
+	// do, however long a step is. An episode is over once the agent's snake
+	// has run out of lives.
+	Snake sim.SnakeConfig
+	Rule  sim.Rule
+	// Opponents make what drives the other snakes at the start of each
+	// episode, one snake for each. The snake at index 0 is the agent's.
+	Opponents []func(index int) sim.Controller
+	// StepTime is how many seconds of game time go by every step. If it's
+	// not positive, it's as long as the agent's snake takes to move one
+	// square at its starting speed.
+	StepTime float64
+	// MaxSteps is how many steps an episode can go on for before it's cut
+	// short. Episodes can go on for ever if it's not positive.
+	MaxSteps int
+	// Rewards is what the agent earns for what happens to its snake. It's
+	// DefaultRewards if it's nil.
+	Rewards     *Rewards
+	Observation Observation
+}
+
+// Info is what happened to the agent's snake by the end of a step.
+type Info struct {
+	Score int
+	Lives int
+	Steps int
+	// Ate and Died are set if the agent's snake ate an item or died during
+	// the step.
+	Ate  bool
+	Died bool
+	// Cause is why the agent's snake died, if it did.
+	Cause events.Cause
+	// Truncated is set if the episode was cut short after MaxSteps.
+	Truncated bool
+}
+
+// Env plays episodes of the game for an agent.
+type Env struct {
+	config   Config
+	stepTime float64
+	rewards  Rewards
+
+	world *sim.World
+	state sim.WorldState
+	t     float64
+	steps int
+	done  bool
+
+	// what happened to the agent's snake during the current step.
+	ate   int
+	died  bool
+	cause events.Cause
+}
+
+// New creates an Env for config. Reset has to be called to start the first
+// episode.
+func New(config Config) *Env {
+	// a Continuous snake that moves a whole square in one step can pass
+	// through its own neck, so snakes here only ever move square by square.
+	config.Snake.Movement = sim.Grid
+	rewards := DefaultRewards
+	if config.Rewards != nil {
+		rewards = *config.Rewards
+	}
+	stepTime := config.StepTime
+	if stepTime <= 0 {
+		speed := config.Snake.PixelsPerSec
+		if speed <= 0 {
+			speed = sim.DefaultPixelsPerSecond
+		}
+		difficulty := config.Snake.Difficulty
+		if difficulty.SpeedScale <= 0 {
+			difficulty.SpeedScale = 1
+		}
+		stepTime = 1 / difficulty.Speed(speed, difficulty.Level(0))
+	}
+	return &Env{
+		config:   config,
+		stepTime: stepTime,
+		rewards:  rewards,
+	}
+}
+
+// Reset starts a new episode, with items placed using seed, and returns the
+// first observation of it. The same seed gives the same episode, as long as
+// the agent and its opponents do the same things.
+func (e *Env) Reset(seed int64) []float64 {
+	bus := events.NewBus()
+	events.Subscribe(bus, e.itemEaten)
+	events.Subscribe(bus, e.snakeDied)
+	e.world = sim.NewGame(bus, e.config.Edges, e.config.Snake, e.config.Rule, len(e.config.Opponents)+1, seed)
+	for i, opponent := range e.config.Opponents {
+		e.world.SetController(i+1, opponent(i+1))
+	}
+
+	e.state = e.world.State()
+	e.t = 0
+	e.steps = 0
+	e.done = false
+	return e.observe()
+}
+
+// Step turns the agent's snake the way action says, moves the world on by one
+// step, and returns what the agent sees afterwards, the reward it earned and
+// whether the episode is over. Once it's over, Step does nothing until Reset
+// is called.
+func (e *Env) Step(action sim.Direction) ([]float64, float64, bool, Info) {
+	if e.world == nil || e.done {
+		return e.observe(), 0, true, e.info()
+	}
+
+	before := e.state
+	e.ate = 0
+	e.died = false
+	e.cause = 0
+	if action != sim.None {
+		e.world.Snakes()[0].SetDirection(action)
+	}
+	e.world.Steer(e.t)
+	e.world.Step(e.t, e.stepTime)
+	e.t += e.stepTime
+	e.steps++
+	e.state = e.world.State()
+
+	me := e.state.Snakes[0]
+	won := e.state.Won && e.state.Winner == 0
+	e.done = me.Lives <= 0 || e.state.Over
+	reward := e.rewards.reward(before, e.state, e.ate, e.died, won)
+	info := e.info()
+	if !e.done && e.config.MaxSteps > 0 && e.steps >= e.config.MaxSteps {
+		e.done = true
+		info.Truncated = true
+	}
+	return e.observe(), reward, e.done, info
+}
+
+// Shape returns the shape of the observations, as Observation.Shape does.
+func (e *Env) Shape() []int {
+	return e.config.Observation.Shape(e.config.Edges)
+}
+
+// State returns the world as it was at the end of the last step.
+func (e *Env) State() sim.WorldState {
+	return e.state
+}
+
+func (e *Env) info() Info {
+	info := Info{
+		Steps: e.steps,
+		Ate:   e.ate > 0,
+		Died:  e.died,
+		Cause: e.cause,
+	}
+	if len(e.state.Snakes) > 0 {
+		info.Score = e.state.Snakes[0].Score
+		info.Lives = e.state.Snakes[0].Lives
+	}
+	return info
+}
+
+func (e *Env) observe() []float64 {
+	if e.world == nil {
+		return nil
+	}
+	return e.config.Observation.observe(e.state)
+}
+
+func (e *Env) itemEaten(ev events.ItemEaten) {
+	if ev.Player == 0 {
+		e.ate++
+	}
+}
+
+func (e *Env) snakeDied(ev events.SnakeDied) {
+	if ev.Player == 0 {
+		e.died = true
+		e.cause = ev.Cause
+	}
+}
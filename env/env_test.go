@@ -0,0 +1,266 @@
+package env
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/kristinaspring/snake-go/ai"
+	"github.com/kristinaspring/snake-go/events"
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+func testConfig() Config {
+	return Config{
+		Edges: sim.Edges{Right: 10, Top: 8},
+		Snake: sim.SnakeConfig{PixelsPerSec: 5, StartingFrames: 3, FramesToGrow: 1, Lives: 1},
+		Rule:  sim.BothDie,
+		Opponents: []func(index int) sim.Controller{
+			func(index int) sim.Controller { return ai.NewGreedy(index) },
+		},
+		MaxSteps: 300,
+	}
+}
+
+// step is everything a step returned.
+type step struct {
+	obs    []float64
+	reward float64
+	done   bool
+	info   Info
+}
+
+// play plays an episode with seed, choosing actions at random with
+// actionSeed, and returns every step of it.
+func play(e *Env, seed int64, actionSeed int64) []step {
+	r := rand.New(rand.NewSource(actionSeed))
+	steps := []step{{obs: e.Reset(seed)}}
+	for done := false; !done; {
+		var s step
+		s.obs, s.reward, s.done, s.info = e.Step(sim.Direction(r.Intn(Actions)))
+		steps = append(steps, s)
+		done = s.done
+	}
+	return steps
+}
+
+func TestDeterministic(t *testing.T) {
+	e := New(testConfig())
+	want := play(e, 1, 2)
+	if len(want) < 3 {
+		t.Fatalf("episode only went on for %d steps", len(want)-1)
+	}
+	if got := play(New(testConfig()), 1, 2); !reflect.DeepEqual(got, want) {
+		t.Error("another Env played the same episode differently")
+	}
+	// resetting starts the episode all over again.
+	if got := play(e, 1, 2); !reflect.DeepEqual(got, want) {
+		t.Error("the same Env played the same episode differently after Reset")
+	}
+	if got := play(e, 3, 2); reflect.DeepEqual(got, want) {
+		t.Error("another seed played the same episode")
+	}
+}
+
+func TestParallel(t *testing.T) {
+	want := play(New(testConfig()), 1, 2)
+
+	const n = 8
+	got := make([][]step, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i] = play(New(testConfig()), 1, 2)
+		}(i)
+	}
+	wg.Wait()
+	for i := range got {
+		if !reflect.DeepEqual(got[i], want) {
+			t.Errorf("Env %d played the episode differently", i)
+		}
+	}
+}
+
+// towards returns which way to go to get closer to the item.
+func towards(world sim.WorldState) sim.Direction {
+	head := sim.Square(world.Snakes[0].Locations[0])
+	switch {
+	case head.X() < world.Item.X():
+		return sim.Right
+	case head.X() > world.Item.X():
+		return sim.Left
+	case head.Y() < world.Item.Y():
+		return sim.Up
+	default:
+		return sim.Down
+	}
+}
+
+func TestRewards(t *testing.T) {
+	config := testConfig()
+	config.Opponents = nil
+	config.Rewards = &Rewards{Item: 10, Death: -5, Step: 0.5, Closer: 2}
+	e := New(config)
+	e.Reset(1)
+
+	// chase a few items, earning for each step closer.
+	for ate := 0; ate < 3; {
+		before := e.State()
+		_, reward, done, info := e.Step(towards(before))
+		if done {
+			t.Fatalf("episode ended after %d steps chasing the item", info.Steps)
+		}
+		want := 0.5
+		if info.Ate {
+			want += 10
+			ate++
+		} else {
+			from, _ := distance(before)
+			to, _ := distance(e.State())
+			want += 2 * (from - to)
+		}
+		if reward != want {
+			t.Errorf("step %d: reward = %v, want %v", info.Steps, reward, want)
+		}
+	}
+
+	// then run into a wall.
+	for {
+		_, reward, done, info := e.Step(sim.Up)
+		if !done {
+			continue
+		}
+		if !info.Died || info.Cause != events.CauseWall || info.Lives != 0 || info.Truncated {
+			t.Errorf("last step's info = %+v, want a death by the wall", info)
+		}
+		if reward != -5 {
+			t.Errorf("reward for dying = %v, want -5", reward)
+		}
+		break
+	}
+	// once it's over, stepping does nothing.
+	if _, reward, done, _ := e.Step(sim.Down); reward != 0 || !done {
+		t.Errorf("Step() after the end = %v, %v, want 0, true", reward, done)
+	}
+}
+
+func TestZeroRewards(t *testing.T) {
+	// rewards that are all zero are kept, rather than being the defaults.
+	config := testConfig()
+	config.Rewards = &Rewards{}
+	for _, s := range play(New(config), 1, 2) {
+		if s.reward != 0 {
+			t.Fatalf("step %d: reward = %v, want 0", s.info.Steps, s.reward)
+		}
+	}
+
+	config.Rewards = nil
+	e := New(config)
+	e.Reset(1)
+	if _, reward, _, _ := e.Step(sim.Up); reward != DefaultRewards.Step {
+		t.Errorf("reward = %v, want DefaultRewards.Step", reward)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	config := testConfig()
+	config.Opponents = nil
+	config.MaxSteps = 5
+	e := New(config)
+	e.Reset(1)
+	// a snake that hasn't been pointed anywhere stays where it is.
+	for i := 1; i <= 5; i++ {
+		_, _, done, info := e.Step(sim.None)
+		if done != (i == 5) || info.Truncated != (i == 5) || info.Steps != i {
+			t.Errorf("step %d: done = %v, info = %+v", i, done, info)
+		}
+	}
+}
+
+func TestLivesLeft(t *testing.T) {
+	config := testConfig()
+	config.Opponents = nil
+	config.Snake.Lives = 2
+	e := New(config)
+	e.Reset(1)
+	for {
+		_, _, done, info := e.Step(sim.Up)
+		if done {
+			t.Fatalf("episode ended with a life left: %+v", info)
+		}
+		if info.Died {
+			if info.Lives != 1 {
+				t.Errorf("lives = %d, want 1", info.Lives)
+			}
+			break
+		}
+	}
+}
+
+func TestShape(t *testing.T) {
+	config := testConfig()
+	for _, test := range []struct {
+		observation Observation
+		shape       []int
+	}{
+		{observation: Features, shape: []int{NumFeatures}},
+		{observation: Grid, shape: []int{GridChannels, 8, 10}},
+	} {
+		config.Observation = test.observation
+		e := New(config)
+		if shape := e.Shape(); !reflect.DeepEqual(shape, test.shape) {
+			t.Errorf("Shape() = %v, want %v", shape, test.shape)
+		}
+		size := 1
+		for _, n := range test.shape {
+			size *= n
+		}
+		obs := e.Reset(1)
+		if len(obs) != size {
+			t.Errorf("observation has %d numbers, want %d", len(obs), size)
+		}
+		if obs, _, _, _ := e.Step(sim.Up); len(obs) != size {
+			t.Errorf("observation after a step has %d numbers, want %d", len(obs), size)
+		}
+	}
+
+	// the grid marks the agent's head and the item where they are.
+	config.Observation = Grid
+	e := New(config)
+	obs := e.Reset(1)
+	state := e.State()
+	head := sim.Square(state.Snakes[0].Locations[0])
+	plane := 8 * 10
+	at := func(channel int, l sim.Location) float64 {
+		return obs[channel*plane+int(l.Y())*10+int(l.X())]
+	}
+	if at(0, head) != 1 || at(4, state.Item) != 1 {
+		t.Errorf("grid doesn't mark the head at %v and the item at %v", head, state.Item)
+	}
+}
+
+func TestAlwaysGrid(t *testing.T) {
+	// snakes set up to move continuously still move square by square.
+	config := testConfig()
+	config.Snake.Movement = sim.Continuous
+	config.StepTime = 0.1
+	e := New(config)
+	e.Reset(1)
+	for i := 0; i < 20; i++ {
+		_, _, done, _ := e.Step(sim.Right)
+		for _, s := range e.State().Snakes {
+			for _, l := range s.Locations {
+				if l.X() != math.Floor(l.X()) || l.Y() != math.Floor(l.Y()) {
+					t.Fatalf("step %d: a snake is at %v, between squares", i, l)
+				}
+			}
+		}
+		if done {
+			break
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package env
+
+import (
+	"math"
+
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+// Observation is how the agent sees the world.
+type Observation int
+
+const (
+	// Features are NumFeatures numbers describing what's around the agent's
+	// snake, in this order:
+	//
+	//   - 1 for each of straight ahead, left and right if the square that way
+	//     has a wall or a snake in it, or 0 if it's free. Before the snake
+	//     has started moving, straight ahead is up.
+	//   - 1 for which of up, down, right and left the snake is going, and 0
+	//     for the others.
+	//   - 1 for each of up, down, right and left if the item is that way, or
+	//     0 if it isn't.
+	//   - how far right and up the item is, as a fraction of the board's
+	//     width and height.
+	//   - how long the snake is, as a fraction of the squares on the board.
+	//
+	// They're all 0 while the snake is off the board.
+	Features Observation = iota
+	// Grid is GridChannels planes the size of the board, one after the other,
+	// marking with a 1 every square that has in it, in order: the agent's
+	// head, the rest of the agent's snake, other snakes' heads, the rest of
+	// the other snakes, and the item. Each plane goes along the rows, starting
+	// from the bottom left.
+	Grid
+)
+
+const (
+	NumFeatures  = 14
+	GridChannels = 5
+)
+
+// Shape returns the shape of the observations on a board with edges: the
+// number of features, or the number of channels, rows and columns.
+func (o Observation) Shape(edges sim.Edges) []int {
+	if o == Grid {
+		return []int{GridChannels, int(edges.Top - edges.Bottom), int(edges.Right - edges.Left)}
+	}
+	return []int{NumFeatures}
+}
+
+func (o Observation) observe(world sim.WorldState) []float64 {
+	if o == Grid {
+		return grid(world)
+	}
+	return features(world)
+}
+
+func grid(world sim.WorldState) []float64 {
+	width := int(world.Edges.Right - world.Edges.Left)
+	height := int(world.Edges.Top - world.Edges.Bottom)
+	plane := width * height
+	obs := make([]float64, GridChannels*plane)
+	mark := func(channel int, l sim.Location) {
+		x := int(math.Floor(l.X() - world.Edges.Left))
+		y := int(math.Floor(l.Y() - world.Edges.Bottom))
+		if x < 0 || x >= width || y < 0 || y >= height {
+			return
+		}
+		obs[channel*plane+y*width+x] = 1
+	}
+
+	for i, s := range world.Snakes {
+		// the agent's own snake goes in the first two planes.
+		channel := 0
+		if i != 0 {
+			channel = 2
+		}
+		for j, l := range s.Locations {
+			if j == 0 {
+				mark(channel, l)
+			} else {
+				mark(channel+1, l)
+			}
+		}
+	}
+	mark(4, world.Item)
+	return obs
+}
+
+func features(world sim.WorldState) []float64 {
+	obs := make([]float64, NumFeatures)
+	me := world.Snakes[0]
+	if len(me.Locations) == 0 {
+		return obs
+	}
+
+	head := sim.Square(me.Locations[0])
+	ahead := me.Direction
+	if ahead == sim.None {
+		ahead = sim.Up
+	}
+	for i, d := range []sim.Direction{ahead, left(ahead), left(ahead).Opposite()} {
+		if sim.Blocked(world, head.Next(d)) {
+			obs[i] = 1
+		}
+	}
+
+	for i, d := range []sim.Direction{sim.Up, sim.Down, sim.Right, sim.Left} {
+		if me.Direction == d {
+			obs[3+i] = 1
+		}
+	}
+
+	dx, dy, _ := toItem(world)
+	for i, toward := range []bool{dy > 0, dy < 0, dx > 0, dx < 0} {
+		if toward {
+			obs[7+i] = 1
+		}
+	}
+	width := world.Edges.Right - world.Edges.Left
+	height := world.Edges.Top - world.Edges.Bottom
+	if width > 0 && height > 0 {
+		obs[11] = dx / width
+		obs[12] = dy / height
+		obs[13] = float64(squares(me)) / (width * height)
+	}
+	return obs
+}
+
+// toItem returns how far right and up the item is from the agent's head, the
+// short way round if the board wraps, if its snake is on the board.
+func toItem(world sim.WorldState) (float64, float64, bool) {
+	me := world.Snakes[0]
+	if len(me.Locations) == 0 {
+		return 0, 0, false
+	}
+	head := sim.Square(me.Locations[0])
+	if world.Wrap {
+		dx, dy := world.Edges.Delta(head, world.Item)
+		return dx, dy, true
+	}
+	return world.Item.X() - head.X(), world.Item.Y() - head.Y(), true
+}
+
+// squares counts the squares s is in.
+func squares(s sim.SnakeState) int {
+	seen := make(map[sim.Location]bool, len(s.Locations))
+	for _, l := range s.Locations {
+		seen[sim.Square(l)] = true
+	}
+	return len(seen)
+}
+
+// left returns the direction to the left of d.
+func left(d sim.Direction) sim.Direction {
+	switch d {
+	case sim.Up:
+		return sim.Left
+	case sim.Left:
+		return sim.Down
+	case sim.Down:
+		return sim.Right
+	case sim.Right:
+		return sim.Up
+	default:
+		return sim.None
+	}
+}
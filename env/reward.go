@@ -0,0 +1,58 @@
+package env
+
+import (
+	"math"
+
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+// Rewards is how much reward the agent earns for each thing that can happen
+// to its snake during a step. Penalties are negative rewards.
+type Rewards struct {
+	// Item is for every item the snake eats.
+	Item float64
+	// Death is for the snake dying, whether or not it has lives left.
+	Death float64
+	// Step is for every step the snake is alive at the end of, to hurry it
+	// along or keep it going.
+	Step float64
+	// Closer is for every square closer to the item the snake's head gets.
+	// Moving further away earns the same amount taken off.
+	Closer float64
+	// Win is for filling the board.
+	Win float64
+}
+
+var DefaultRewards = Rewards{Item: 1, Death: -1, Step: -0.01}
+
+// reward adds up what the agent earned over a step that went from before to
+// after, in which its snake ate that many items and died if died is set.
+func (r Rewards) reward(before sim.WorldState, after sim.WorldState, ate int, died bool, won bool) float64 {
+	reward := float64(ate) * r.Item
+	if died {
+		reward += r.Death
+	} else if after.Snakes[0].Lives > 0 && len(after.Snakes[0].Locations) > 0 {
+		reward += r.Step
+	}
+	if won {
+		reward += r.Win
+	}
+
+	// only count the snake getting closer when it moved along without eating
+	// or dying, and the item stayed where it was.
+	if r.Closer != 0 && ate == 0 && !died && before.Item.Equal(after.Item) {
+		from, ok := distance(before)
+		to, ok2 := distance(after)
+		if ok && ok2 {
+			reward += r.Closer * (from - to)
+		}
+	}
+	return reward
+}
+
+// distance is how many squares the agent's head is from the item, if its
+// snake is on the board.
+func distance(world sim.WorldState) (float64, bool) {
+	dx, dy, ok := toItem(world)
+	return math.Abs(dx) + math.Abs(dy), ok
+}
@@ -0,0 +1,82 @@
+// Package events lets the parts of the game tell each other what happened,
+// without having to know who is listening.
+package events
+
+import "sync"
+
+// Event is something that happened in the game. Only this package defines
+// events.
+type Event interface {
+	event()
+}
+
+// Bus delivers published events to everything subscribed to them. Events are
+// delivered synchronously, on the goroutine that publishes them, in the order
+// the subscriptions were made. A nil *Bus drops everything published to it,
+// and subscribing to one does nothing.
+type Bus struct {
+	lock     sync.RWMutex
+	nextID   int
+	handlers []handler
+}
+
+type handler struct {
+	id int
+	fn func(Event)
+}
+
+// NewBus creates a Bus with no subscribers.
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe calls fn with every event of type E published to b. The returned
+// function removes the subscription.
+func Subscribe[E Event](b *Bus, fn func(E)) func() {
+	return b.SubscribeAll(func(e Event) {
+		if typed, ok := e.(E); ok {
+			fn(typed)
+		}
+	})
+}
+
+// SubscribeAll calls fn with every event published to b. The returned
+// function removes the subscription.
+func (b *Bus) SubscribeAll(fn func(Event)) func() {
+	if b == nil {
+		return func() {}
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.handlers = append(b.handlers, handler{id: id, fn: fn})
+
+	return func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		for i, h := range b.handlers {
+			if h.id == id {
+				b.handlers = append(b.handlers[:i:i], b.handlers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish delivers e to its subscribers. Subscribers may publish more events
+// or change subscriptions while handling e.
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+
+	b.lock.RLock()
+	handlers := b.handlers
+	b.lock.RUnlock()
+
+	for _, h := range handlers {
+		h.fn(e)
+	}
+}
@@ -0,0 +1,45 @@
+package events
+
+import "testing"
+
+func TestNilBus(t *testing.T) {
+	var b *Bus
+	called := false
+	unsubscribe := Subscribe(b, func(ItemEaten) {
+		called = true
+	})
+	unsubscribeAll := b.SubscribeAll(func(Event) {
+		called = true
+	})
+	b.Publish(ItemEaten{})
+	unsubscribe()
+	unsubscribeAll()
+
+	if called {
+		t.Error("a nil Bus delivered an event")
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	b := NewBus()
+	var eaten []int
+	var all int
+	unsubscribe := Subscribe(b, func(e ItemEaten) {
+		eaten = append(eaten, e.Player)
+	})
+	b.SubscribeAll(func(Event) {
+		all++
+	})
+
+	b.Publish(ItemEaten{Player: 1})
+	b.Publish(BoardFull{})
+	unsubscribe()
+	b.Publish(ItemEaten{Player: 2})
+
+	if len(eaten) != 1 || eaten[0] != 1 {
+		t.Errorf("ItemEaten delivered for players %v, want [1]", eaten)
+	}
+	if all != 3 {
+		t.Errorf("SubscribeAll was given %d events, want 3", all)
+	}
+}
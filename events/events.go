@@ -0,0 +1,98 @@
+package events
+
+// Cause is why a snake died.
+type Cause int
+
+const (
+	CauseWall Cause = iota
+	CauseSelf
+	CauseSnake
+	CauseDisqualified
+)
+
+func (c Cause) String() string {
+	switch c {
+	case CauseWall:
+		return "wall"
+	case CauseSelf:
+		return "self"
+	case CauseSnake:
+		return "snake"
+	case CauseDisqualified:
+		return "disqualified"
+	default:
+		return "unknown"
+	}
+}
+
+// ItemPlaced is published when an item is put somewhere on the board.
+type ItemPlaced struct {
+	X int
+	Y int
+}
+
+// BoardFull is published when an item can't be placed because there's no
+// free square left on the board.
+type BoardFull struct{}
+
+// ItemEaten is published when a player's snake eats an item.
+type ItemEaten struct {
+	Player int
+	X      int
+	Y      int
+}
+
+// SnakeDied is published when a player's snake dies. Score is what the player
+// had when it happened and Lives is how many lives they have left.
+type SnakeDied struct {
+	Player int
+	Cause  Cause
+	Score  int
+	Lives  int
+}
+
+// ScoreChanged is published whenever a player's score goes up or is reset.
+type ScoreChanged struct {
+	Player int
+	Score  int
+}
+
+// LevelChanged is published when a player's snake goes up a level.
+type LevelChanged struct {
+	Player int
+	Level  int
+}
+
+// RoundOver is published at the end of a tick in which any snake died. Scores
+// has every player's score at the end of the round, using the score they died
+// with for those that did.
+type RoundOver struct {
+	Scores []int
+}
+
+// GameOver is published once every snake has run out of lives. Scores has
+// every player's final score.
+type GameOver struct {
+	Scores []int
+}
+
+// Victory is published when a player wins by filling the board. Scores has
+// every player's final score.
+type Victory struct {
+	Player int
+	Scores []int
+}
+
+// GameStarted is published when a game that was over is started again.
+type GameStarted struct{}
+
+func (ItemPlaced) event()   {}
+func (BoardFull) event()    {}
+func (ItemEaten) event()    {}
+func (SnakeDied) event()    {}
+func (ScoreChanged) event() {}
+func (LevelChanged) event() {}
+func (RoundOver) event()    {}
+func (GameOver) event()     {}
+func (Victory) event()      {}
+func (GameStarted) event()  {}
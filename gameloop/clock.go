@@ -0,0 +1,55 @@
+package gameloop
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for a Loop.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Sleep waits for d to pass.
+	Sleep(d time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// ManualClock is a Clock that only moves when it is told to. It allows a Loop
+// to be driven deterministically, without depending on the wall clock.
+type ManualClock struct {
+	lock sync.Mutex
+	now  time.Time
+}
+
+// NewManualClock creates a ManualClock stopped at start.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the time the clock is currently stopped at.
+func (c *ManualClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// Sleep moves the clock forward by d instead of waiting.
+func (c *ManualClock) Sleep(d time.Duration) {
+	c.Advance(d)
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	c.now = c.now.Add(d)
+	c.lock.Unlock()
+}
@@ -1,56 +1,369 @@
 package gameloop
 
 import (
-	"fmt"
+	"context"
+	"math"
+	"runtime/debug"
+	"sync"
 	"time"
 )
 
-// StartLoop is physics game loop based on https://gafferongames.com/post/fix_your_timestep/
-func StartLoop(handler GameHandler, updateRate time.Duration, startingState interface{}) chan<- struct{} {
+// Controller controls a running Loop. It is safe to use from any goroutine,
+// including from inside Integrate and Render.
+type Controller interface {
+	// Pause stops Integrate from being called until Resume. Render keeps
+	// being called.
+	Pause()
+	// Resume undoes Pause.
+	Resume()
+	// Paused reports whether the loop is paused.
+	Paused() bool
+	// QueueStep makes a paused loop integrate a single tick on its next
+	// frame. It has no effect while the loop is running.
+	QueueStep()
+	// SetTimeScale multiplies the time that passes between frames by scale,
+	// so values below 1 give slow motion and values above 1 fast forward.
+	SetTimeScale(scale float64)
+	// TimeScale returns the current time scale.
+	TimeScale() float64
+}
+
+// Loop is a physics game loop based on https://gafferongames.com/post/fix_your_timestep/
+// It can either be run in real time with Start or StartIntegration, or be
+// driven by hand with Frame, Step and AdvanceBy. A Loop is also a Controller.
+type Loop[S any] struct {
+	handler      GameHandler[S]
+	clock        Clock
+	deltaTime    float64
+	maxFrameTime float64
+	observer     Observer
+
+	// only touched by whatever is integrating.
+	accumulator float64
+	dropped     float64
+	currentTime time.Time
+
+	// only touched by whatever is rendering.
+	lastFrame time.Time
+
+	// guards the results of integrating, which may be rendered from another
+	// goroutine.
+	stateLock sync.RWMutex
+	t         float64
+	ticks     uint64
+	lastTick  time.Time
+	previous  S
+	current   S
+
+	lock      sync.Mutex
+	paused    bool
+	steps     int
+	timeScale float64
+}
+
+// NewLoop creates a Loop that calls Integrate once every updateRate. If clock
+// is nil, the wall clock is used.
+func NewLoop[S any](handler GameHandler[S], updateRate time.Duration, startingState S, clock Clock) *Loop[S] {
+	if clock == nil {
+		clock = realClock{}
+	}
+	now := clock.Now()
+	return &Loop[S]{
+		handler:      handler,
+		clock:        clock,
+		deltaTime:    updateRate.Seconds(),
+		maxFrameTime: time.Duration(time.Second / 4).Seconds(),
+		observer:     noopObserver{},
+		currentTime:  now,
+		lastFrame:    now,
+		lastTick:     now,
+		previous:     startingState,
+		current:      startingState,
+		timeScale:    1,
+	}
+}
 
-	var t float64
-	deltaTime := updateRate.Seconds()
+// SetObserver sets what is told about the frames and ticks of the loop. It
+// must be called before the loop is started.
+func (l *Loop[S]) SetObserver(o Observer) {
+	if o == nil {
+		o = noopObserver{}
+	}
+	l.observer = o
+}
 
-	currentTime := time.Now()
-	var accumulator float64
+// StartLoop is physics game loop based on https://gafferongames.com/post/fix_your_timestep/
+// It runs until ctx is done.
+func StartLoop[S any](ctx context.Context, handler GameHandler[S], updateRate time.Duration, startingState S) *Handle {
+	return NewLoop(handler, updateRate, startingState, nil).Start(ctx)
+}
+
+// Start runs the loop in a new goroutine until ctx is done.
+func (l *Loop[S]) Start(ctx context.Context) *Handle {
+	return start(ctx, l.Run)
+}
 
-	var (
-		previous interface{}
-		current  interface{}
-	)
-	current = startingState
+// StartIntegration integrates ticks in a new goroutine until ctx is done, but
+// never renders. Rendering is left to the caller through RenderFrame, so it
+// can happen on a thread of the caller's choosing, like the main thread
+// pixelgl requires.
+func (l *Loop[S]) StartIntegration(ctx context.Context) *Handle {
+	return start(ctx, l.RunIntegration)
+}
 
-	stopChann := make(chan struct{}, 0)
-	maxFrameTime := time.Duration(time.Second / 4).Seconds()
+func start(ctx context.Context, run func(context.Context) error) *Handle {
+	h := &Handle{
+		done: make(chan struct{}),
+	}
 
 	go func() {
-		for {
-			select {
-			case <-stopChann:
-				fmt.Println("stopping loop")
-				return
-			default:
-				newTime := time.Now()
-				frameTime := newTime.Sub(currentTime).Seconds()
-				if frameTime > maxFrameTime {
-					frameTime = maxFrameTime
-				}
-
-				currentTime = newTime
-				accumulator += frameTime
-
-				for accumulator >= deltaTime {
-					previous = current
-					current = handler.Integrate(current, t, deltaTime)
-					t += deltaTime
-					accumulator -= deltaTime
-				}
-
-				alpha := accumulator / deltaTime
-
-				handler.Render(current, t, 1.0-alpha)
+		h.err = run(ctx)
+		close(h.done)
+	}()
+	return h
+}
+
+// Run runs frames until ctx is done, sleeping in between them until the next
+// tick is due. If Integrate or Render panics, the panic is recovered and
+// returned as a *PanicError.
+func (l *Loop[S]) Run(ctx context.Context) (err error) {
+	defer l.recoverPanic(&err)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			l.Frame()
+			l.clock.Sleep(l.untilNextTick())
+		}
+	}
+}
+
+// RunIntegration integrates ticks until ctx is done, sleeping in between
+// them. If Integrate panics, the panic is recovered and returned as a
+// *PanicError.
+func (l *Loop[S]) RunIntegration(ctx context.Context) (err error) {
+	defer l.recoverPanic(&err)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			l.update()
+			l.clock.Sleep(l.untilNextTick())
+		}
+	}
+}
+
+// RenderFrame renders the most recent states once. It's meant to be used
+// alongside StartIntegration, and works out alpha from how long ago the last
+// tick was integrated. If Render panics, the panic is recovered and returned
+// as a *PanicError.
+func (l *Loop[S]) RenderFrame() (err error) {
+	defer l.recoverPanic(&err)
+
+	l.stateLock.RLock()
+	previous, current, t, lastTick := l.previous, l.current, l.t, l.lastTick
+	l.stateLock.RUnlock()
+
+	alpha := l.clock.Now().Sub(lastTick).Seconds() * l.TimeScale() / l.deltaTime
+	if alpha > 1 {
+		alpha = 1
+	}
+
+	l.handler.Render(previous, current, t, alpha)
+	l.frameRendered()
+	return nil
+}
+
+// Frame reads the clock, integrates as many ticks as the time since the last
+// frame allows and then renders once. The time between frames is capped so a
+// long stall doesn't cause a spiral of updates. Frame honours the pause, step
+// and time scale controls.
+func (l *Loop[S]) Frame() {
+	l.update()
+	l.render()
+}
+
+// AdvanceBy runs a frame as though d had passed since the last one, without
+// consulting the clock. Unlike Frame, d is not capped and the controls are
+// ignored.
+func (l *Loop[S]) AdvanceBy(d time.Duration) {
+	l.accumulate(d.Seconds())
+	l.render()
+}
+
+// Step integrates exactly n ticks and then renders once. Time left over from
+// previous frames is kept as is.
+func (l *Loop[S]) Step(n int) {
+	for i := 0; i < n; i++ {
+		l.integrate()
+	}
+	l.render()
+}
+
+// State returns the state produced by the most recent Integrate.
+func (l *Loop[S]) State() S {
+	l.stateLock.RLock()
+	defer l.stateLock.RUnlock()
+	return l.current
+}
+
+// Time returns the game time in seconds.
+func (l *Loop[S]) Time() float64 {
+	l.stateLock.RLock()
+	defer l.stateLock.RUnlock()
+	return l.t
+}
+
+// Ticks returns the number of times Integrate has been called.
+func (l *Loop[S]) Ticks() uint64 {
+	l.stateLock.RLock()
+	defer l.stateLock.RUnlock()
+	return l.ticks
+}
+
+func (l *Loop[S]) Pause() {
+	l.lock.Lock()
+	l.paused = true
+	l.lock.Unlock()
+}
+
+func (l *Loop[S]) Resume() {
+	l.lock.Lock()
+	l.paused = false
+	l.steps = 0
+	l.lock.Unlock()
+}
+
+func (l *Loop[S]) Paused() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.paused
+}
+
+func (l *Loop[S]) QueueStep() {
+	l.lock.Lock()
+	if l.paused {
+		l.steps++
+	}
+	l.lock.Unlock()
+}
+
+func (l *Loop[S]) SetTimeScale(scale float64) {
+	if scale <= 0 {
+		return
+	}
+	l.lock.Lock()
+	l.timeScale = scale
+	l.lock.Unlock()
+}
+
+func (l *Loop[S]) TimeScale() float64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.timeScale
+}
+
+// update reads the clock and the controls and integrates every tick that is
+// due.
+func (l *Loop[S]) update() {
+	l.lock.Lock()
+	paused := l.paused
+	steps := l.steps
+	l.steps = 0
+	timeScale := l.timeScale
+	l.lock.Unlock()
+
+	newTime := l.clock.Now()
+	frameTime := newTime.Sub(l.currentTime).Seconds()
+	if frameTime > l.maxFrameTime {
+		if !paused {
+			// keep the fractions of a tick around so they add up over time.
+			l.dropped += (frameTime - l.maxFrameTime) * timeScale / l.deltaTime
+			n := int(l.dropped)
+			l.dropped -= float64(n)
+			if n > 0 {
+				l.observer.TicksDropped(n)
 			}
 		}
-	}()
-	return stopChann
+		frameTime = l.maxFrameTime
+	}
+	l.currentTime = newTime
+
+	if paused {
+		for i := 0; i < steps; i++ {
+			l.integrate()
+		}
+		return
+	}
+	l.accumulate(frameTime * timeScale)
+}
+
+func (l *Loop[S]) accumulate(frameTime float64) {
+	l.accumulator += frameTime
+
+	for l.accumulator >= l.deltaTime {
+		l.integrate()
+		l.accumulator -= l.deltaTime
+	}
+	l.observer.Backlog(time.Duration(l.accumulator * float64(time.Second)))
+}
+
+// untilNextTick is how long the goroutine running the loop can sleep for
+// before another tick is due.
+func (l *Loop[S]) untilNextTick() time.Duration {
+	l.lock.Lock()
+	paused := l.paused
+	timeScale := l.timeScale
+	l.lock.Unlock()
+
+	remaining := l.deltaTime
+	if !paused {
+		remaining = (l.deltaTime - l.accumulator) / timeScale
+	}
+	// round up so the tick is actually due once the sleep is over.
+	return time.Duration(math.Ceil(remaining * float64(time.Second)))
+}
+
+func (l *Loop[S]) integrate() {
+	start := l.clock.Now()
+	next := l.handler.Integrate(l.current, l.t, l.deltaTime)
+	end := l.clock.Now()
+
+	l.stateLock.Lock()
+	l.previous = l.current
+	l.current = next
+	l.t += l.deltaTime
+	l.ticks++
+	l.lastTick = end
+	l.stateLock.Unlock()
+
+	l.observer.TickIntegrated(end, end.Sub(start))
+}
+
+func (l *Loop[S]) render() {
+	alpha := l.accumulator / l.deltaTime
+
+	l.handler.Render(l.previous, l.current, l.t, alpha)
+	l.frameRendered()
+}
+
+func (l *Loop[S]) frameRendered() {
+	now := l.clock.Now()
+	l.observer.FrameRendered(now, now.Sub(l.lastFrame))
+	l.lastFrame = now
+}
+
+func (l *Loop[S]) recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = &PanicError{
+			Value: r,
+			Tick:  l.Ticks(),
+			Time:  l.Time(),
+			Stack: debug.Stack(),
+		}
+	}
 }
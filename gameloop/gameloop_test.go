@@ -0,0 +1,448 @@
+package gameloop
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// tick is a sixteenth of a second, which adds up without rounding errors.
+const tick = time.Second / 16
+
+// counter is a game whose state is the number of times it's been integrated.
+// It keeps what it was last asked to render.
+type counter struct {
+	previous, current int
+	t, alpha          float64
+	renders           int
+}
+
+func (c *counter) Integrate(currentState int, _ float64, _ float64) int {
+	return currentState + 1
+}
+
+func (c *counter) Render(previous int, current int, t float64, alpha float64) {
+	c.previous, c.current = previous, current
+	c.t, c.alpha = t, alpha
+	c.renders++
+}
+
+// dropCounter counts the ticks a loop drops.
+type dropCounter struct {
+	noopObserver
+	dropped int
+}
+
+func (d *dropCounter) TicksDropped(n int) {
+	d.dropped += n
+}
+
+// recorder keeps everything an Observer is told.
+type recorder struct {
+	frames   []time.Duration
+	ticks    []time.Time
+	dropped  int
+	backlogs []time.Duration
+}
+
+func (r *recorder) FrameRendered(_ time.Time, frameTime time.Duration) {
+	r.frames = append(r.frames, frameTime)
+}
+
+func (r *recorder) TickIntegrated(at time.Time, _ time.Duration) {
+	r.ticks = append(r.ticks, at)
+}
+
+func (r *recorder) TicksDropped(n int) {
+	r.dropped += n
+}
+
+func (r *recorder) Backlog(d time.Duration) {
+	r.backlogs = append(r.backlogs, d)
+}
+
+func newTestLoop() (*Loop[int], *counter, *ManualClock) {
+	clock := NewManualClock(time.Unix(0, 0))
+	game := &counter{}
+	return NewLoop[int](game, tick, 0, clock), game, clock
+}
+
+func TestAdvanceBy(t *testing.T) {
+	tests := []struct {
+		name      string
+		d         time.Duration
+		wantTicks uint64
+		wantAlpha float64
+	}{
+		{name: "nothing", d: 0, wantTicks: 0, wantAlpha: 0},
+		{name: "half a tick", d: tick / 2, wantTicks: 0, wantAlpha: 0.5},
+		{name: "one tick", d: tick, wantTicks: 1, wantAlpha: 0},
+		{name: "three and a quarter ticks", d: 3*tick + tick/4, wantTicks: 3, wantAlpha: 0.25},
+		// AdvanceBy isn't capped like Frame is.
+		{name: "a second", d: time.Second, wantTicks: 16, wantAlpha: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loop, game, _ := newTestLoop()
+			loop.AdvanceBy(test.d)
+
+			if got := loop.Ticks(); got != test.wantTicks {
+				t.Errorf("Ticks() = %d, want %d", got, test.wantTicks)
+			}
+			if got := loop.State(); got != int(test.wantTicks) {
+				t.Errorf("State() = %d, want %d", got, test.wantTicks)
+			}
+			if want := float64(test.wantTicks) * tick.Seconds(); loop.Time() != want {
+				t.Errorf("Time() = %v, want %v", loop.Time(), want)
+			}
+			if game.renders != 1 {
+				t.Errorf("rendered %d times, want 1", game.renders)
+			}
+			if game.alpha != test.wantAlpha {
+				t.Errorf("alpha = %v, want %v", game.alpha, test.wantAlpha)
+			}
+		})
+	}
+}
+
+func TestAdvanceByCarriesOver(t *testing.T) {
+	loop, game, _ := newTestLoop()
+	for i := 0; i < 3; i++ {
+		loop.AdvanceBy(tick / 2)
+	}
+
+	if loop.Ticks() != 1 {
+		t.Errorf("Ticks() = %d, want 1", loop.Ticks())
+	}
+	if game.previous != 0 || game.current != 1 {
+		t.Errorf("rendered %d to %d, want 0 to 1", game.previous, game.current)
+	}
+	if game.alpha != 0.5 {
+		t.Errorf("alpha = %v, want 0.5", game.alpha)
+	}
+}
+
+func TestFrameClampsFrameTime(t *testing.T) {
+	loop, game, clock := newTestLoop()
+	drops := &dropCounter{}
+	loop.SetObserver(drops)
+
+	// a one second stall only gets to integrate a quarter of a second.
+	clock.Advance(time.Second)
+	loop.Frame()
+
+	if loop.Ticks() != 4 {
+		t.Errorf("Ticks() = %d, want 4", loop.Ticks())
+	}
+	if drops.dropped != 12 {
+		t.Errorf("dropped %d ticks, want 12", drops.dropped)
+	}
+	if game.alpha != 0 {
+		t.Errorf("alpha = %v, want 0", game.alpha)
+	}
+
+	// frames under the cap aren't touched.
+	clock.Advance(2*tick + tick/2)
+	loop.Frame()
+
+	if loop.Ticks() != 6 {
+		t.Errorf("Ticks() = %d, want 6", loop.Ticks())
+	}
+	if drops.dropped != 12 {
+		t.Errorf("dropped %d ticks, want 12", drops.dropped)
+	}
+	if game.alpha != 0.5 {
+		t.Errorf("alpha = %v, want 0.5", game.alpha)
+	}
+}
+
+func TestStep(t *testing.T) {
+	loop, game, clock := newTestLoop()
+	loop.AdvanceBy(tick / 4)
+	// Step doesn't look at the clock.
+	clock.Advance(time.Second)
+	loop.Step(3)
+
+	if loop.Ticks() != 3 {
+		t.Errorf("Ticks() = %d, want 3", loop.Ticks())
+	}
+	if game.previous != 2 || game.current != 3 {
+		t.Errorf("rendered %d to %d, want 2 to 3", game.previous, game.current)
+	}
+	if want := 3 * tick.Seconds(); game.t != want {
+		t.Errorf("t = %v, want %v", game.t, want)
+	}
+	// the time left over from before is kept.
+	if game.alpha != 0.25 {
+		t.Errorf("alpha = %v, want 0.25", game.alpha)
+	}
+	if game.renders != 2 {
+		t.Errorf("rendered %d times, want 2", game.renders)
+	}
+
+	loop.Step(0)
+	if loop.Ticks() != 3 || game.renders != 3 {
+		t.Errorf("Step(0) integrated %d ticks and rendered %d times, want 3 and 3", loop.Ticks(), game.renders)
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	loop, game, clock := newTestLoop()
+	loop.Pause()
+	if !loop.Paused() {
+		t.Fatal("Paused() = false after Pause")
+	}
+
+	// a paused loop keeps rendering but doesn't integrate.
+	clock.Advance(3 * tick)
+	loop.Frame()
+	if loop.Ticks() != 0 || game.renders != 1 {
+		t.Errorf("paused Frame integrated %d ticks and rendered %d times, want 0 and 1", loop.Ticks(), game.renders)
+	}
+
+	// the time spent paused isn't made up for afterwards.
+	loop.Resume()
+	if loop.Paused() {
+		t.Fatal("Paused() = true after Resume")
+	}
+	clock.Advance(tick)
+	loop.Frame()
+	if loop.Ticks() != 1 {
+		t.Errorf("Ticks() = %d, want 1", loop.Ticks())
+	}
+}
+
+func TestQueueStep(t *testing.T) {
+	loop, _, clock := newTestLoop()
+
+	// steps can't be queued up while the loop is running.
+	loop.QueueStep()
+	loop.Pause()
+	loop.Frame()
+	if loop.Ticks() != 0 {
+		t.Fatalf("Ticks() = %d, want 0", loop.Ticks())
+	}
+
+	// each step queued while paused integrates one tick on the next frame,
+	// however little time has gone by.
+	loop.QueueStep()
+	loop.QueueStep()
+	loop.Frame()
+	if loop.Ticks() != 2 {
+		t.Errorf("Ticks() = %d, want 2", loop.Ticks())
+	}
+	clock.Advance(time.Second)
+	loop.Frame()
+	if loop.Ticks() != 2 {
+		t.Errorf("Ticks() = %d, want 2 once the steps are used up", loop.Ticks())
+	}
+
+	// resuming throws away steps that haven't been taken.
+	loop.QueueStep()
+	loop.Resume()
+	loop.Frame()
+	if loop.Ticks() != 2 {
+		t.Errorf("Ticks() = %d, want 2 after resuming", loop.Ticks())
+	}
+}
+
+func TestSetTimeScale(t *testing.T) {
+	tests := []struct {
+		name      string
+		scale     float64
+		wantScale float64
+		wantTicks uint64
+	}{
+		{name: "normal", scale: 1, wantScale: 1, wantTicks: 4},
+		{name: "fast forward", scale: 2, wantScale: 2, wantTicks: 8},
+		{name: "slow motion", scale: 0.5, wantScale: 0.5, wantTicks: 2},
+		// scales that aren't positive are ignored.
+		{name: "zero", scale: 0, wantScale: 1, wantTicks: 4},
+		{name: "negative", scale: -2, wantScale: 1, wantTicks: 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loop, _, clock := newTestLoop()
+			loop.SetTimeScale(test.scale)
+			if got := loop.TimeScale(); got != test.wantScale {
+				t.Errorf("TimeScale() = %v, want %v", got, test.wantScale)
+			}
+
+			clock.Advance(4 * tick)
+			loop.Frame()
+			if got := loop.Ticks(); got != test.wantTicks {
+				t.Errorf("Ticks() = %d, want %d", got, test.wantTicks)
+			}
+			// game time goes by a tick at a time whatever the scale.
+			if want := float64(test.wantTicks) * tick.Seconds(); loop.Time() != want {
+				t.Errorf("Time() = %v, want %v", loop.Time(), want)
+			}
+		})
+	}
+}
+
+// panicky is a game that panics on the tick it's told to, in Integrate or
+// in the Render that follows it.
+type panicky struct {
+	on       int
+	inRender bool
+}
+
+func (p *panicky) Integrate(currentState int, _ float64, _ float64) int {
+	if !p.inRender && currentState == p.on {
+		panic("integrate")
+	}
+	return currentState + 1
+}
+
+func (p *panicky) Render(_ int, current int, _ float64, _ float64) {
+	if p.inRender && current == p.on {
+		panic("render")
+	}
+}
+
+func TestPanicIsReturnedByWait(t *testing.T) {
+	tests := []struct {
+		name      string
+		game      *panicky
+		wantValue string
+		wantTicks uint64
+	}{
+		{name: "integrate", game: &panicky{on: 2}, wantValue: "integrate", wantTicks: 2},
+		{name: "render", game: &panicky{on: 4, inRender: true}, wantValue: "render", wantTicks: 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clock := NewManualClock(time.Unix(0, 0))
+			loop := NewLoop[int](test.game, tick, 0, clock)
+			// the first frame has enough time for four ticks.
+			clock.Advance(time.Second)
+
+			err := loop.Start(context.Background()).Wait()
+			var p *PanicError
+			if !errors.As(err, &p) {
+				t.Fatalf("Wait() = %v, want a *PanicError", err)
+			}
+			if p.Value != test.wantValue {
+				t.Errorf("Value = %v, want %v", p.Value, test.wantValue)
+			}
+			if p.Tick != test.wantTicks {
+				t.Errorf("Tick = %d, want %d", p.Tick, test.wantTicks)
+			}
+			if want := float64(test.wantTicks) * tick.Seconds(); p.Time != want {
+				t.Errorf("Time = %v, want %v", p.Time, want)
+			}
+			if len(p.Stack) == 0 {
+				t.Error("Stack is empty")
+			}
+		})
+	}
+}
+
+func TestCancelStopsLoop(t *testing.T) {
+	loop, _, _ := newTestLoop()
+	ctx, cancel := context.WithCancel(context.Background())
+	h := loop.Start(ctx)
+	cancel()
+
+	select {
+	case <-h.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("loop didn't stop once its context was done")
+	}
+	if err := h.Wait(); err != nil {
+		t.Errorf("Wait() = %v, want nil", err)
+	}
+}
+
+// stopper is a counter that cancels its context once it's been integrated
+// enough times.
+type stopper struct {
+	counter
+	stopAt int
+	cancel context.CancelFunc
+}
+
+func (s *stopper) Integrate(currentState int, t float64, deltaT float64) int {
+	if currentState+1 >= s.stopAt {
+		s.cancel()
+	}
+	return s.counter.Integrate(currentState, t, deltaT)
+}
+
+func TestRunSleepsBetweenFrames(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	game := &stopper{stopAt: 10, cancel: cancel}
+	loop := NewLoop[int](game, tick, 0, clock)
+
+	// the clock only moves when the loop sleeps, so a loop that spins never
+	// gets anywhere.
+	h := loop.Start(ctx)
+	select {
+	case <-h.Done():
+	case <-time.After(5 * time.Second):
+		cancel()
+		t.Fatal("loop didn't sleep until the next tick")
+	}
+	if err := h.Wait(); err != nil {
+		t.Fatalf("Wait() = %v, want nil", err)
+	}
+
+	if loop.Ticks() != 10 {
+		t.Errorf("Ticks() = %d, want 10", loop.Ticks())
+	}
+	// a frame for the start and one for each tick after it.
+	if game.renders != 11 {
+		t.Errorf("rendered %d times, want 11", game.renders)
+	}
+	// and a sleep after every frame.
+	if got := clock.Now().Sub(time.Unix(0, 0)); got != 11*tick {
+		t.Errorf("slept for %v, want %v", got, 11*tick)
+	}
+}
+
+func TestObserver(t *testing.T) {
+	loop, _, clock := newTestLoop()
+	r := &recorder{}
+	loop.SetObserver(r)
+
+	clock.Advance(2*tick + tick/2)
+	loop.Frame()
+	clock.Advance(time.Second)
+	loop.Frame()
+
+	if len(r.ticks) != 6 {
+		t.Errorf("told about %d ticks, want 6", len(r.ticks))
+	}
+	for _, at := range r.ticks[2:] {
+		if want := time.Unix(0, 0).Add(time.Second + 2*tick + tick/2); !at.Equal(want) {
+			t.Errorf("tick integrated at %v, want %v", at, want)
+		}
+	}
+	// the second frame was capped at four ticks and left a half behind.
+	if r.dropped != 12 {
+		t.Errorf("dropped %d ticks, want 12", r.dropped)
+	}
+	wantBacklogs := []time.Duration{tick / 2, tick / 2}
+	wantFrames := []time.Duration{2*tick + tick/2, time.Second}
+	for i := range wantBacklogs {
+		if i >= len(r.backlogs) || r.backlogs[i] != wantBacklogs[i] {
+			t.Fatalf("backlogs = %v, want %v", r.backlogs, wantBacklogs)
+		}
+		if i >= len(r.frames) || r.frames[i] != wantFrames[i] {
+			t.Fatalf("frame times = %v, want %v", r.frames, wantFrames)
+		}
+	}
+
+	// a nil Observer is the same as none.
+	loop.SetObserver(nil)
+	loop.AdvanceBy(tick)
+	if len(r.ticks) != 6 {
+		t.Errorf("told about %d ticks after the observer was taken away, want 6", len(r.ticks))
+	}
+}
@@ -0,0 +1,38 @@
+package gameloop
+
+import "fmt"
+
+// Handle is a loop running in its own goroutine.
+type Handle struct {
+	done chan struct{}
+	err  error
+}
+
+// Done is closed once the loop has stopped.
+func (h *Handle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait blocks until the loop has stopped. It returns nil if the loop stopped
+// because its context was done, or a *PanicError if the game panicked.
+func (h *Handle) Wait() error {
+	<-h.done
+	return h.err
+}
+
+// PanicError is a panic recovered from Integrate or Render.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Tick is the number of ticks that had been integrated when the panic
+	// happened.
+	Tick uint64
+	// Time is the game time in seconds when the panic happened.
+	Time float64
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("game loop panicked on tick %d at %.3fs: %v", e.Tick, e.Time, e.Value)
+}
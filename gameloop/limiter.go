@@ -0,0 +1,42 @@
+package gameloop
+
+import "time"
+
+// Limiter paces a loop, such as a render loop, to a target number of frames
+// per second.
+type Limiter struct {
+	clock Clock
+	frame time.Duration
+	next  time.Time
+}
+
+// NewLimiter creates a Limiter for fps frames per second. If fps isn't
+// positive, Wait never blocks. If clock is nil, the wall clock is used.
+func NewLimiter(fps int, clock Clock) *Limiter {
+	if clock == nil {
+		clock = realClock{}
+	}
+	l := &Limiter{
+		clock: clock,
+		next:  clock.Now(),
+	}
+	if fps > 0 {
+		l.frame = time.Second / time.Duration(fps)
+	}
+	return l
+}
+
+// Wait sleeps until the next frame is due. If the caller has fallen more than
+// a frame behind, the schedule is reset rather than rushing to catch up.
+func (l *Limiter) Wait() {
+	if l.frame <= 0 {
+		return
+	}
+	l.next = l.next.Add(l.frame)
+	now := l.clock.Now()
+	if l.next.Before(now) {
+		l.next = now
+		return
+	}
+	l.clock.Sleep(l.next.Sub(now))
+}
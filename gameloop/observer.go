@@ -0,0 +1,28 @@
+package gameloop
+
+import "time"
+
+// Observer is told what a Loop is doing, so it can be measured. When
+// integrating and rendering happen on different goroutines, its methods are
+// called from both.
+type Observer interface {
+	// FrameRendered is called after every Render with the time it finished
+	// and how long it has been since the previous one finished.
+	FrameRendered(at time.Time, frameTime time.Duration)
+	// TickIntegrated is called after every Integrate with the time it
+	// finished and how long it took.
+	TickIntegrated(at time.Time, d time.Duration)
+	// TicksDropped is called when the time between frames is capped, with
+	// the number of ticks that will never be integrated because of it.
+	TicksDropped(n int)
+	// Backlog is called with the time left over once all of the ticks that
+	// are due have been integrated.
+	Backlog(d time.Duration)
+}
+
+type noopObserver struct{}
+
+func (noopObserver) FrameRendered(time.Time, time.Duration)  {}
+func (noopObserver) TickIntegrated(time.Time, time.Duration) {}
+func (noopObserver) TicksDropped(int)                        {}
+func (noopObserver) Backlog(time.Duration)                   {}
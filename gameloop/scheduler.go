@@ -0,0 +1,159 @@
+package gameloop
+
+import "time"
+
+// TimerID identifies a timer added to a Scheduler.
+type TimerID int
+
+type system struct {
+	name string
+	rate time.Duration
+	next time.Duration
+	fn   func(t float64, deltaT float64)
+}
+
+type timer struct {
+	id     TimerID
+	due    time.Duration
+	repeat time.Duration
+	fn     func(t float64)
+}
+
+// Scheduler runs systems at their own fixed rates, and timers after a delay,
+// as time is fed to it through Update. It's meant to be updated from
+// Integrate, so parts of a game can run at rates other than the loop's. A
+// Scheduler is not safe for concurrent use.
+type Scheduler struct {
+	now     time.Duration
+	systems []*system
+	timers  map[TimerID]*timer
+	nextID  TimerID
+}
+
+// NewScheduler creates a Scheduler with no systems or timers.
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		timers: make(map[TimerID]*timer),
+	}
+}
+
+// AddSystem runs fn once every rate. t is the time in seconds and deltaT is
+// always rate in seconds. Systems that are due at the same time run in the
+// order they were added. A system with a rate that isn't positive would never
+// let time move on, so it isn't added at all.
+func (s *Scheduler) AddSystem(name string, rate time.Duration, fn func(t float64, deltaT float64)) {
+	if rate <= 0 {
+		return
+	}
+	s.systems = append(s.systems, &system{
+		name: name,
+		rate: rate,
+		next: s.now + rate,
+		fn:   fn,
+	})
+}
+
+// RemoveSystem stops the systems with the given name from running.
+func (s *Scheduler) RemoveSystem(name string) {
+	systems := s.systems[:0]
+	for _, sys := range s.systems {
+		if sys.name != name {
+			systems = append(systems, sys)
+		}
+	}
+	s.systems = systems
+}
+
+// After runs fn once, d from now. If d is negative, fn runs on the next
+// Update.
+func (s *Scheduler) After(d time.Duration, fn func(t float64)) TimerID {
+	return s.addTimer(d, 0, fn)
+}
+
+// Every runs fn every d, starting d from now, until it's cancelled. If d isn't
+// positive, nothing is added and Every returns 0, which is never the ID of a
+// timer.
+func (s *Scheduler) Every(d time.Duration, fn func(t float64)) TimerID {
+	if d <= 0 {
+		return 0
+	}
+	return s.addTimer(d, d, fn)
+}
+
+func (s *Scheduler) addTimer(d time.Duration, repeat time.Duration, fn func(t float64)) TimerID {
+	if d < 0 {
+		d = 0
+	}
+	s.nextID++
+	s.timers[s.nextID] = &timer{
+		id:     s.nextID,
+		due:    s.now + d,
+		repeat: repeat,
+		fn:     fn,
+	}
+	return s.nextID
+}
+
+// Cancel stops a timer from running again. It reports whether the timer was
+// still waiting to run.
+func (s *Scheduler) Cancel(id TimerID) bool {
+	_, ok := s.timers[id]
+	delete(s.timers, id)
+	return ok
+}
+
+// Time returns the time in seconds the scheduler has been updated up to.
+func (s *Scheduler) Time() float64 {
+	return s.now.Seconds()
+}
+
+// Update moves the scheduler forward by deltaT seconds, running everything
+// that comes due in the order it comes due. When a system and a timer are due
+// at the same time, the system runs first.
+func (s *Scheduler) Update(deltaT float64) {
+	target := s.now + time.Duration(deltaT*float64(time.Second)+0.5)
+
+	for {
+		sys := s.nextSystem()
+		tmr := s.nextTimer()
+
+		switch {
+		case sys != nil && sys.next <= target && (tmr == nil || sys.next <= tmr.due):
+			s.now = sys.next
+			sys.next += sys.rate
+			sys.fn(s.now.Seconds(), sys.rate.Seconds())
+		case tmr != nil && tmr.due <= target:
+			s.now = tmr.due
+			if tmr.repeat > 0 {
+				tmr.due += tmr.repeat
+			} else {
+				delete(s.timers, tmr.id)
+			}
+			tmr.fn(s.now.Seconds())
+		default:
+			s.now = target
+			return
+		}
+	}
+}
+
+func (s *Scheduler) nextSystem() *system {
+	var next *system
+	for _, sys := range s.systems {
+		if next == nil || sys.next < next.next {
+			next = sys
+		}
+	}
+	return next
+}
+
+func (s *Scheduler) nextTimer() *timer {
+	var next *timer
+	for _, t := range s.timers {
+		// break ties by id so timers added first run first.
+		if next == nil || t.due < next.due || (t.due == next.due && t.id < next.id) {
+			next = t
+		}
+	}
+	return next
+}
@@ -0,0 +1,159 @@
+package gameloop
+
+import (
+	"testing"
+	"time"
+)
+
+// calls records what a scheduler ran and when.
+type calls []string
+
+func (c *calls) add(name string, t float64) {
+	*c = append(*c, name+"@"+time.Duration(t*float64(time.Second)).String())
+}
+
+func (c calls) equal(want []string) bool {
+	if len(c) != len(want) {
+		return false
+	}
+	for i := range c {
+		if c[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSchedulerSystems(t *testing.T) {
+	s := NewScheduler()
+	var got calls
+	var deltas []float64
+	s.AddSystem("fast", 100*time.Millisecond, func(t float64, deltaT float64) {
+		got.add("fast", t)
+		deltas = append(deltas, deltaT)
+	})
+	s.AddSystem("slow", 250*time.Millisecond, func(t float64, _ float64) {
+		got.add("slow", t)
+	})
+	// systems that aren't given a rate never run.
+	s.AddSystem("never", 0, func(t float64, _ float64) {
+		got.add("never", t)
+	})
+
+	s.Update(0.25)
+	want := []string{"fast@100ms", "fast@200ms", "slow@250ms"}
+	if !got.equal(want) {
+		t.Errorf("ran %v, want %v", got, want)
+	}
+	for _, d := range deltas {
+		if d != 0.1 {
+			t.Errorf("deltaT = %v, want 0.1", d)
+		}
+	}
+
+	got = nil
+	s.RemoveSystem("slow")
+	s.Update(0.25)
+	want = []string{"fast@300ms", "fast@400ms", "fast@500ms"}
+	if !got.equal(want) {
+		t.Errorf("ran %v, want %v", got, want)
+	}
+	if s.Time() != 0.5 {
+		t.Errorf("Time() = %v, want 0.5", s.Time())
+	}
+}
+
+func TestSchedulerTimers(t *testing.T) {
+	s := NewScheduler()
+	var got calls
+	s.After(300*time.Millisecond, func(t float64) {
+		got.add("once", t)
+	})
+	every := s.Every(200*time.Millisecond, func(t float64) {
+		got.add("every", t)
+	})
+	cancelled := s.After(100*time.Millisecond, func(t float64) {
+		got.add("cancelled", t)
+	})
+	// a system runs before a timer that's due at the same time.
+	s.AddSystem("system", 200*time.Millisecond, func(t float64, _ float64) {
+		got.add("system", t)
+	})
+
+	if !s.Cancel(cancelled) {
+		t.Error("Cancel of a waiting timer = false, want true")
+	}
+	if s.Cancel(cancelled) {
+		t.Error("Cancel of a cancelled timer = true, want false")
+	}
+
+	s.Update(0.5)
+	want := []string{"system@200ms", "every@200ms", "once@300ms", "system@400ms", "every@400ms"}
+	if !got.equal(want) {
+		t.Errorf("ran %v, want %v", got, want)
+	}
+
+	got = nil
+	if !s.Cancel(every) {
+		t.Error("Cancel of a repeating timer = false, want true")
+	}
+	s.Update(0.5)
+	want = []string{"system@600ms", "system@800ms", "system@1s"}
+	if !got.equal(want) {
+		t.Errorf("ran %v, want %v", got, want)
+	}
+}
+
+func TestSchedulerTimersAddedTogether(t *testing.T) {
+	s := NewScheduler()
+	var got calls
+	for _, name := range []string{"first", "second", "third"} {
+		name := name
+		s.After(0, func(t float64) {
+			got.add(name, t)
+		})
+	}
+	// a timer added while running runs in the same update if it's due.
+	s.After(100*time.Millisecond, func(t float64) {
+		got.add("outer", t)
+		s.After(50*time.Millisecond, func(t float64) {
+			got.add("inner", t)
+		})
+	})
+
+	s.Update(0.2)
+	want := []string{"first@0s", "second@0s", "third@0s", "outer@100ms", "inner@150ms"}
+	if !got.equal(want) {
+		t.Errorf("ran %v, want %v", got, want)
+	}
+}
+
+func TestSchedulerRatesThatArentPositive(t *testing.T) {
+	s := NewScheduler()
+	var got calls
+	for _, d := range []time.Duration{0, -time.Second} {
+		d := d
+		s.AddSystem("system", d, func(t float64, _ float64) {
+			got.add("system "+d.String(), t)
+		})
+		if id := s.Every(d, func(t float64) {
+			got.add("every "+d.String(), t)
+		}); id != 0 {
+			t.Errorf("Every(%v) = %d, want 0", d, id)
+		}
+	}
+	if s.Cancel(0) {
+		t.Error("Cancel(0) = true, want false")
+	}
+	// a timer that's already due runs straight away.
+	s.After(-time.Second, func(t float64) {
+		got.add("late", t)
+	})
+
+	s.Update(0)
+	s.Update(1)
+	want := []string{"late@0s"}
+	if !got.equal(want) {
+		t.Errorf("ran %v, want %v", got, want)
+	}
+}
@@ -1,16 +1,19 @@
 package gameloop
 
-type GameHandler interface {
+// GameHandler is a game that can be run by a Loop. S is the type of the game's
+// state.
+type GameHandler[S any] interface {
 	// Integrate handles a logical step in the game, it must return the next state of the game
 	// currentState is the current state of the game.
 	// t is the time in seconds
 	// deltaT is the time since the last update
-	Integrate(currentState interface{}, t float64, deltaT float64) interface{}
+	Integrate(currentState S, t float64, deltaT float64) S
 
 	// Render should handle all the Rendering logic of the game.
 	// _note:_ only display logic should go here
-	// state is the current state of the game.
+	// previous is the state of the game before the most recent Integrate.
+	// current is the state returned by the most recent Integrate.
 	// t is the time in seconds
-	// alpha is the progression in between display frames. This allows for liner interpolation.
-	Render(state interface{}, t float64, alpha float64)
-}
\ No newline at end of file
+	// alpha is the progression from previous to current, between 0 and 1. This allows for liner interpolation.
+	Render(previous S, current S, t float64, alpha float64)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/kristinaspring/snake-go/events"
+)
+
+// hud keeps track of what the heads up display shows, based on the game's
+// events.
+type hud struct {
+	lock   sync.Mutex
+	scores []int
+	levels []int
+}
+
+func newHUD(bus *events.Bus, players int) *hud {
+	h := &hud{
+		scores: make([]int, players),
+		levels: make([]int, players),
+	}
+	for i := range h.levels {
+		h.levels[i] = 1
+	}
+	events.Subscribe(bus, h.scoreChanged)
+	events.Subscribe(bus, h.levelChanged)
+	events.Subscribe(bus, h.gameStarted)
+	return h
+}
+
+func (h *hud) scoreChanged(e events.ScoreChanged) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if e.Player < 0 || e.Player >= len(h.scores) {
+		return
+	}
+	h.scores[e.Player] = e.Score
+}
+
+func (h *hud) levelChanged(e events.LevelChanged) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if e.Player < 0 || e.Player >= len(h.levels) {
+		return
+	}
+	h.levels[e.Player] = e.Level
+}
+
+// gameStarted puts everyone back on the first level. Scores are reset by
+// their own events.
+func (h *hud) gameStarted(_ events.GameStarted) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for i := range h.levels {
+		h.levels[i] = 1
+	}
+}
+
+func (h *hud) score(player int) int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.scores[player]
+}
+
+func (h *hud) level(player int) int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.levels[player]
+}
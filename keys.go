@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+type keyBinding struct {
+	button    pixelgl.Button
+	direction sim.Direction
+}
+
+// defaultKeys are the keys for the first players, for when they don't have
+// any of their own.
+var defaultKeys = [][]keyBinding{
+	{{pixelgl.KeyLeft, sim.Left}, {pixelgl.KeyRight, sim.Right}, {pixelgl.KeyDown, sim.Down}, {pixelgl.KeyUp, sim.Up}},
+	{{pixelgl.KeyA, sim.Left}, {pixelgl.KeyD, sim.Right}, {pixelgl.KeyS, sim.Down}, {pixelgl.KeyW, sim.Up}},
+}
+
+// action is something done with a key other than turning.
+type action int
+
+const (
+	pauseAction action = iota
+	stepAction
+	slowerAction
+	fasterAction
+	normalSpeedAction
+	statsAction
+	restartAction
+	screenshotAction
+	remapAction
+	numActions
+)
+
+var actionNames = [numActions]string{
+	"pause", "step", "slower", "faster", "normal speed", "stats", "restart", "screenshot", "remap keys",
+}
+
+func (a action) String() string {
+	return actionNames[a]
+}
+
+// controls holds the key for every action.
+type controls [numActions]pixelgl.Button
+
+// defaultControls are the keys for the actions that aren't given any.
+var defaultControls = controls{
+	pauseAction:       pixelgl.KeyP,
+	stepAction:        pixelgl.KeyPeriod,
+	slowerAction:      pixelgl.KeyMinus,
+	fasterAction:      pixelgl.KeyEqual,
+	normalSpeedAction: pixelgl.Key0,
+	statsAction:       pixelgl.KeyF3,
+	restartAction:     pixelgl.KeyR,
+	screenshotAction:  pixelgl.KeyF12,
+	remapAction:       pixelgl.KeyF1,
+}
+
+// buttons holds every keyboard button by its lower case name, like "up", "w"
+// or "kp8".
+var buttons = func() map[string]pixelgl.Button {
+	b := make(map[string]pixelgl.Button)
+	for k := pixelgl.KeySpace; k <= pixelgl.KeyLast; k++ {
+		if name := k.String(); name != "Invalid" {
+			b[strings.ToLower(name)] = k
+		}
+	}
+	return b
+}()
+
+// GetButton looks up a keyboard button by its name, ignoring case.
+func GetButton(name string) (pixelgl.Button, error) {
+	b, ok := buttons[strings.ToLower(name)]
+	if !ok {
+		return pixelgl.KeyUnknown, fmt.Errorf("unknown key %q", name)
+	}
+	return b, nil
+}
+
+// buttonName names b for showing to players.
+func buttonName(b pixelgl.Button) string {
+	if b == pixelgl.KeyUnknown {
+		return "-"
+	}
+	return b.String()
+}
+
+// playerBindings returns the keys player index turns with, one for each
+// direction. A player without any keys set gets the default ones for their
+// index, if there are any. Directions without a key are bound to
+// pixelgl.KeyUnknown.
+func playerBindings(index int, keys KeysConfig) ([]keyBinding, error) {
+	if keys == (KeysConfig{}) {
+		if index < len(defaultKeys) {
+			return append([]keyBinding(nil), defaultKeys[index]...), nil
+		}
+	}
+
+	var bindings []keyBinding
+	for _, k := range []struct {
+		name      string
+		direction sim.Direction
+	}{
+		{keys.Left, sim.Left},
+		{keys.Right, sim.Right},
+		{keys.Down, sim.Down},
+		{keys.Up, sim.Up},
+	} {
+		b := pixelgl.KeyUnknown
+		if k.name != "" {
+			var err error
+			b, err = GetButton(k.name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		bindings = append(bindings, keyBinding{b, k.direction})
+	}
+	return bindings, nil
+}
+
+// controlBindings returns the key for every action, using the default for
+// any that aren't set.
+func controlBindings(config ControlsConfig) (controls, error) {
+	c := defaultControls
+	for a, name := range [numActions]string{
+		pauseAction:       config.Pause,
+		stepAction:        config.Step,
+		slowerAction:      config.Slower,
+		fasterAction:      config.Faster,
+		normalSpeedAction: config.NormalSpeed,
+		statsAction:       config.Stats,
+		restartAction:     config.Restart,
+		screenshotAction:  config.Screenshot,
+		remapAction:       config.Remap,
+	} {
+		if name == "" {
+			continue
+		}
+		b, err := GetButton(name)
+		if err != nil {
+			return c, fmt.Errorf("%v: %w", action(a), err)
+		}
+		c[a] = b
+	}
+	return c, nil
+}
+
+// keySlot is somewhere a key can be bound, named for showing to players.
+type keySlot struct {
+	name   string
+	button *pixelgl.Button
+}
+
+// keySlots lists every player's keys, in order, followed by the actions'.
+func keySlots(names []string, players [][]keyBinding, c *controls) []keySlot {
+	var slots []keySlot
+	for i, bindings := range players {
+		for j := range bindings {
+			slots = append(slots, keySlot{
+				name:   fmt.Sprintf("%s %s", names[i], bindings[j].direction),
+				button: &bindings[j].button,
+			})
+		}
+	}
+	for a := range c {
+		slots = append(slots, keySlot{name: action(a).String(), button: &c[a]})
+	}
+	return slots
+}
+
+// validateKeys makes sure no key is bound in more than one slot.
+func validateKeys(slots []keySlot) error {
+	used := make(map[pixelgl.Button]string)
+	for _, s := range slots {
+		b := *s.button
+		if b == pixelgl.KeyUnknown {
+			continue
+		}
+		if other, ok := used[b]; ok {
+			return fmt.Errorf("%s is used for both %s and %s", b, other, s.name)
+		}
+		used[b] = s.name
+	}
+	return nil
+}
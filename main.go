@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"os"
+	"sync"
 	"time"
 	"unicode"
 
@@ -11,7 +15,11 @@ import (
 	"github.com/faiface/pixel/pixelgl"
 	"github.com/faiface/pixel/text"
 	"github.com/golang/freetype/truetype"
+	"github.com/kristinaspring/snake-go/bot"
+	"github.com/kristinaspring/snake-go/events"
 	"github.com/kristinaspring/snake-go/gameloop"
+	"github.com/kristinaspring/snake-go/metrics"
+	"github.com/kristinaspring/snake-go/sim"
 	"github.com/spf13/viper"
 	"golang.org/x/image/colornames"
 	"golang.org/x/image/font"
@@ -19,25 +27,90 @@ import (
 )
 
 type ViperConfig struct {
-	Board       BoardConfig
-	Snake       SnakeViperConfig
-	Multiplayer MultiplayerConfig
+	Board    BoardConfig
+	Snake    SnakeViperConfig
+	Players  []PlayerConfig
+	Controls ControlsConfig
+	Items    ItemsConfig
+	Metrics  MetricsConfig
 }
 
-type MultiplayerConfig struct {
-	Enable bool
-	Color  string
-	Style  string
+type ItemsConfig struct {
+	// Lifetime is how many seconds an item stays put before moving somewhere
+	// else if nobody eats it. Items never move if it's not positive.
+	Lifetime float64
+}
+
+type MetricsConfig struct {
+	// CSV is where to write the frame and tick telemetry when the game
+	// closes. Nothing is written if it's empty.
+	CSV string
+}
+
+// PlayerConfig describes one of the players, each of which gets a snake.
+type PlayerConfig struct {
+	Name  string
+	Color string
+	Style string
+	// Controller is what drives the player's snake: keyboard, gamepad, ai,
+	// bot, network or replay. It's keyboard unless set otherwise.
+	Controller string
+	Keys       KeysConfig
+	// Gamepad is the number of the gamepad a gamepad player uses, from 1.
+	Gamepad int
+	// Skill is how good an ai player is: easy, normal or hard.
+	Skill string
+	// Command is the program, and its arguments, a bot player runs.
+	Command []string
+	// TimeLimit is how many seconds a bot has to answer each tick before
+	// it's disqualified. It's bot.DefaultTimeLimit if it's not positive.
+	TimeLimit float64
+	// Address is where a network player connects to send their directions,
+	// like ":7001".
+	Address string
+	// Replay is the recording a replay player plays back.
+	Replay string
+	// Record is where to write the player's intents once the game closes,
+	// so they can be played back. Nothing is recorded if it's empty.
+	Record string
+}
+
+// KeysConfig names the keys a player turns with. A player without any keys
+// gets the arrow keys if they're first, WASD if they're second, and none
+// otherwise.
+type KeysConfig struct {
+	Up    string
+	Down  string
+	Left  string
+	Right string
+}
+
+// ControlsConfig names the keys for everything other than turning. Any that
+// are left out keep their defaults.
+type ControlsConfig struct {
+	Pause       string
+	Step        string
+	Slower      string
+	Faster      string
+	NormalSpeed string
+	Stats       string
+	Restart     string
+	Screenshot  string
+	Remap       string
 }
 
 type SnakeViperConfig struct {
-	Color          string
-	Style          string
 	TaperTo        float64
 	Speed          float64
+	Difficulty     string
+	Movement       string
 	StartingFrames int
 	FramesToGrow   int
 	Threshold      float64
+	TurnBuffer     int
+	Lives          int
+	RespawnDelay   float64
+	Invulnerable   float64
 }
 
 type BoardConfig struct {
@@ -48,7 +121,12 @@ type BoardConfig struct {
 	BorderWidth    float64
 	ShowGrid       bool
 	ShowCounters   bool
+	Wrap           bool
+	HeadOn         string
 	TickRate       int
+	InputRate      int
+	TargetFPS      int
+	Seed           int64
 }
 
 func main() {
@@ -96,138 +174,392 @@ func run() {
 	if config.Board.ShowGrid {
 		drawGrid(playingBoard, config.Board.NumSquaresWide, config.Board.NumSquaresHigh, config.Board.Buffer, config.Board.SquareSize)
 	}
-	es := Edges{
-		left:   0,
-		right:  config.Board.NumSquaresWide,
-		bottom: 0,
-		top:    config.Board.NumSquaresHigh,
+	es := sim.Edges{
+		Left:   0,
+		Right:  config.Board.NumSquaresWide,
+		Bottom: 0,
+		Top:    config.Board.NumSquaresHigh,
 	}
-	// set up items for the snake to eat
-	tracker := NewSingleTracker(es, config.Board.SquareSize, config.Board.Buffer, colornames.Indianred)
+	bus := events.NewBus()
 
-	// set up the snake itself
-	c := SnakeConfig{
-		Edges:          es,
+	// set up the snakes, one for each player
+	c := sim.SnakeConfig{
+		Wrap:           config.Board.Wrap,
+		Movement:       sim.GetMovement(config.Snake.Movement),
 		SquareSize:     config.Board.SquareSize,
-		Buffer:         config.Board.Buffer,
-		Colors:         GetColor(config.Snake.Color).GetColors(GetStyle(config.Snake.Style)),
-		TaperTo:        config.Snake.TaperTo,
 		PixelsPerSec:   config.Snake.Speed,
+		Difficulty:     sim.GetDifficulty(config.Snake.Difficulty),
 		StartingFrames: config.Snake.StartingFrames,
 		FramesToGrow:   config.Snake.FramesToGrow,
 		Threshold:      config.Snake.Threshold,
+		TurnBuffer:     config.Snake.TurnBuffer,
+		Lives:          config.Snake.Lives,
+		RespawnDelay:   config.Snake.RespawnDelay,
+		Invulnerable:   config.Snake.Invulnerable,
 	}
 
-	if config.Multiplayer.Enable {
-		middleY := float64(int((es.top-es.bottom)/3.0)) + es.bottom
-		middleX := float64(int((es.right-es.left)/3.0)) + es.left
-		c.StartingPosition = location{x: middleX, y: middleY}
+	players := config.Players
+	if len(players) == 0 {
+		players = []PlayerConfig{{}}
 	}
 
-	snake := NewSnake(tracker, c)
-	snakes := []*Snake{snake}
-
-	if config.Multiplayer.Enable {
-		c.Colors = GetColor(config.Multiplayer.Color).GetColors(GetStyle(config.Multiplayer.Style))
-		middleX := float64(int(2*(es.top-es.bottom)/3.0)) + es.bottom
-		middleY := float64(int(2*(es.right-es.left)/3.0)) + es.left
-		c.StartingPosition = location{x: middleX, y: middleY}
+	replays, err := loadReplays(players)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bad replay: %v\n", err.Error())
+		os.Exit(1)
+	}
+	seed, err := itemSeed(config.Board.Seed, replays)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bad replay: %v\n", err.Error())
+		os.Exit(1)
+	}
 
-		snake2 := NewSnake(tracker, c)
+	world := sim.NewGame(bus, es, c, sim.GetRule(config.Board.HeadOn), len(players), seed)
+	snakes := world.Snakes()
+	renderers := make([]snakeRenderer, len(players))
+	names := make([]string, len(players))
+	for i, p := range players {
+		renderers[i] = snakeRenderer{
+			squareSize: config.Board.SquareSize,
+			buffer:     config.Board.Buffer,
+			taperTo:    config.Snake.TaperTo,
+			colors:     GetColor(p.Color).GetColors(GetStyle(p.Style)),
+			movement:   c.Movement,
+			edges:      es,
+			wrap:       c.Wrap,
+		}
 
-		snake.SetOtherSnake(snake2)
-		snake2.SetOtherSnake(snake)
-		snakes = append(snakes, snake2)
+		names[i] = p.Name
+		if names[i] == "" {
+			names[i] = fmt.Sprintf("P%d", i+1)
+		}
+	}
+	controls, err := controlBindings(config.Controls)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bad controls: %v\n", err)
+		os.Exit(1)
 	}
 
 	g := &Game{
 		playingBoard: playingBoard,
-		tracker:      tracker,
-		window:       win,
-		frameCount:   NewCounter(100),
-		updateCount:  NewCounter(100),
-		playerText:   make([]*text.Text, len(snakes)),
-	}
-	if config.Board.ShowCounters {
-		g.txt = text.New(pixel.V(1, 1), text.NewAtlas(
-			ttfFromBytesMust(goregular.TTF, config.Board.Buffer-2.0),
-			text.ASCII, text.RangeTable(unicode.Latin),
-		))
-	}
-	for index, s := range snakes {
-		t := text.New(pixel.V(config.Board.Buffer+(float64(index)*(windowWidth-config.Board.Buffer*4)), windowHeight-(config.Board.Buffer-4.0)), text.NewAtlas(
-			ttfFromBytesMust(goregular.TTF, config.Board.Buffer-4.0),
-			text.ASCII, text.RangeTable(unicode.Latin),
-		))
-		t.Color = s.config.Colors[0]
+		world:        world,
+		renderers:    renderers,
+		item: itemRenderer{
+			squareSize: config.Board.SquareSize,
+			buffer:     config.Board.Buffer,
+			color:      colornames.Indianred,
+		},
+		scheduler:  gameloop.NewScheduler(),
+		hud:        newHUD(bus, len(snakes)),
+		session:    newSessionStats(bus),
+		window:     win,
+		stats:      metrics.NewRecorder(),
+		showStats:  config.Board.ShowCounters,
+		names:      names,
+		keys:       make([][]keyBinding, len(snakes)),
+		keyboards:  make([]*sim.Queue, len(snakes)),
+		turnBuffer: c.TurnBuffer,
+		replays:    replays,
+		seed:       seed,
+		controls:   controls,
+		playerText: make([]*text.Text, len(snakes)),
+	}
+	for i, p := range players {
+		controller, err := g.addController(i, p)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bad controller for player %s: %v\n", names[i], err)
+			os.Exit(1)
+		}
+		g.world.SetController(i, controller)
+	}
+	slots := keySlots(g.names, g.keys, &g.controls)
+	err = validateKeys(slots)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bad keys: %v\n", err)
+		os.Exit(1)
+	}
+	if config.Board.Wrap {
+		g.frame = NewBoardFrame(windowWidth, windowHeight, boardWidth, boardHeight, config.Board.Buffer, config.Board.BorderWidth)
+	}
+	g.txt = text.New(pixel.V(1, 1), text.NewAtlas(
+		ttfFromBytesMust(goregular.TTF, config.Board.Buffer-2.0),
+		text.ASCII, text.RangeTable(unicode.Latin),
+	))
+	// overlay the top left corner of the board.
+	g.txt.Orig = pixel.V(config.Board.Buffer+4.0, windowHeight-config.Board.Buffer-g.txt.LineHeight)
+	g.txt.Color = colornames.Black
+	playerAtlas := text.NewAtlas(
+		ttfFromBytesMust(goregular.TTF, config.Board.Buffer-4.0),
+		text.ASCII, text.RangeTable(unicode.Latin),
+	)
+	for index, r := range renderers {
+		t := text.New(pixel.ZV, playerAtlas)
+		if len(r.colors) > 0 {
+			t.Color = r.colors[0]
+		}
 		g.playerText[index] = t
 	}
+	// players go above the board first, then below it.
+	g.hudBounds = pixel.R(config.Board.Buffer, playerAtlas.Descent()+2.0, windowWidth-config.Board.Buffer, windowHeight-(config.Board.Buffer-4.0))
+	g.banner = text.New(pixel.V(windowWidth/2, windowHeight/2), text.NewAtlas(
+		ttfFromBytesMust(goregular.TTF, config.Board.Buffer),
+		text.ASCII, text.RangeTable(unicode.Latin),
+	))
+	g.banner.Color = colornames.Black
+	g.remap = newRemapScreen(slots, text.New(pixel.ZV, playerAtlas))
+	g.remap.txt.Color = colornames.Black
+
+	tickRate := time.Second / time.Duration(config.Board.TickRate)
+	inputRate := tickRate
+	if config.Board.InputRate > 0 {
+		inputRate = time.Second / time.Duration(config.Board.InputRate)
+	}
+	g.scheduler.AddSystem("input", inputRate, g.applyInput)
+	g.scheduler.AddSystem("movement", tickRate, g.moveSnakes)
+	if config.Items.Lifetime > 0 {
+		g.itemLifetime = time.Duration(config.Items.Lifetime * float64(time.Second))
+		events.Subscribe(bus, g.itemPlaced)
+		g.itemTimer = g.scheduler.After(g.itemLifetime, g.expireItem)
+	}
 
-	stopChan := gameloop.StartLoop(g, time.Second/time.Duration(config.Board.TickRate), snakes)
+	loop := gameloop.NewLoop[sim.WorldState](g, tickRate, g.world.State(), nil)
+	g.controller = loop
+	loop.SetObserver(g.stats)
+	ctx, cancel := context.WithCancel(context.Background())
+	handle := loop.StartIntegration(ctx)
+	limiter := gameloop.NewLimiter(config.Board.TargetFPS, nil)
 
-	// keep running and updating things until the window is closed.
+	// keep rendering on the main thread until the window is closed or the
+	// loop stops on its own.
+running:
 	for !win.Closed() {
+		select {
+		case <-handle.Done():
+			break running
+		default:
+		}
+		err = loop.RenderFrame()
+		if err != nil {
+			break
+		}
+		limiter.Wait()
 	}
-	stopChan <- struct{}{}
+	cancel()
+	for _, b := range g.bots {
+		if err := b.Forfeited(); err != nil {
+			fmt.Fprintf(os.Stderr, "bot disqualified: %v\n", err.Error())
+		}
+		b.Close()
+	}
+
+	exitOnLoopError(err)
+	exitOnLoopError(handle.Wait())
+
+	err = writeRecordings(g.recordings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write recordings: %v\n", err.Error())
+		os.Exit(1)
+	}
+
+	if config.Metrics.CSV != "" {
+		err = writeMetrics(config.Metrics.CSV, g.stats)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write metrics: %v\n", err.Error())
+			os.Exit(1)
+		}
+	}
+}
+
+func writeMetrics(path string, stats *metrics.Recorder) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = stats.WriteCSV(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func exitOnLoopError(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "game loop failed: %v\n", err.Error())
+	var panicErr *gameloop.PanicError
+	if errors.As(err, &panicErr) {
+		os.Stderr.Write(panicErr.Stack)
+	}
+	os.Exit(1)
 }
 
 type Game struct {
 	playingBoard *imdraw.IMDraw
-	tracker      tracker
-	window       *pixelgl.Window
-	measurement  float64
-	frameCount   *Counter
-	updateCount  *Counter
-	txt          *text.Text
-
+	// frame is drawn over the snakes when the board wraps.
+	frame       *imdraw.IMDraw
+	world       *sim.World
+	renderers   []snakeRenderer
+	item        itemRenderer
+	scheduler   *gameloop.Scheduler
+	hud         *hud
+	session     *sessionStats
+	window      *pixelgl.Window
+	measurement float64
+	stats       *metrics.Recorder
+	showStats   bool
+	txt         *text.Text
+	controller  gameloop.Controller
+
+	names      []string
+	keys       [][]keyBinding
+	controls   controls
 	playerText []*text.Text
+	// the baselines of the top and bottom rows of player text, and how far
+	// across the window they go.
+	hudBounds pixel.Rect
+	// shows the final scores once the game is over.
+	banner *text.Text
+	remap  *remapScreen
+	// whether the loop was paused before the remap screen paused it.
+	pausedBeforeRemap bool
+
+	// the keyboard and gamepad players' controllers, fed on the main
+	// thread. Players who don't use the keyboard have no keyboard. Their
+	// queues hold as many directions as a snake's turn buffer.
+	keyboards  []*sim.Queue
+	gamepads   []*gamepad
+	bots       []*bot.Bot
+	recordings []recording
+	turnBuffer int
+	// the recordings replay players play back, and the seed the game's
+	// items are placed with, which is recorded too.
+	replays []*sim.Replay
+	seed    int64
+
+	// set when restart is pressed, and read when integrating.
+	restartLock sync.Mutex
+	restart     bool
+
+	itemLifetime time.Duration
+	itemTimer    gameloop.TimerID
 }
 
-func (g *Game) Integrate(currentState interface{}, t float64, deltaT float64) interface{} {
-	var snake2 *Snake
+// sampleInput feeds the direction keys each keyboard player has just pressed
+// to their controller, in the order they're applied, so quick presses in
+// between input ticks aren't lost, and does the same for every gamepad. It
+// has to be called from the main thread, after the window has been updated.
+func (g *Game) sampleInput() {
+	for i, q := range g.keyboards {
+		if q == nil {
+			continue
+		}
+		for _, k := range g.keys[i] {
+			if k.button != pixelgl.KeyUnknown && g.window.JustPressed(k.button) {
+				q.Push(k.direction)
+			}
+		}
+	}
+	for _, p := range g.gamepads {
+		p.sample(g.window)
+	}
+}
 
-	snakes := currentState.([]*Snake)
-	snake := snakes[0]
+const (
+	minTimeScale = 1.0 / 16
+	maxTimeScale = 8.0
+)
 
-	if len(snakes) == 2 {
-		snake2 = snakes[1]
+// handleControls lets the loop be paused, stepped and slowed down or sped up
+// from the keyboard, the stats overlay be toggled, screenshots be taken and
+// keys be remapped, using the keys bound to each action. It's called from
+// Render since JustPressed is only accurate once per window update.
+func (g *Game) handleControls() {
+	if g.controller == nil {
+		return
 	}
-
-	if g.window.Pressed(pixelgl.KeyLeft) {
-		snake.SetDirection(Left)
+	if g.remap.open {
+		if !g.remap.update(g.window) && !g.pausedBeforeRemap {
+			g.controller.Resume()
+		}
+		return
 	}
-	if g.window.Pressed(pixelgl.KeyRight) {
-		snake.SetDirection(Right)
+	if g.window.JustPressed(g.controls[remapAction]) {
+		g.pausedBeforeRemap = g.controller.Paused()
+		g.controller.Pause()
+		g.remap.show()
+		return
 	}
-	if g.window.Pressed(pixelgl.KeyDown) {
-		snake.SetDirection(Down)
+	if g.window.JustPressed(g.controls[pauseAction]) {
+		if g.controller.Paused() {
+			g.controller.Resume()
+		} else {
+			g.controller.Pause()
+		}
 	}
-	if g.window.Pressed(pixelgl.KeyUp) {
-		snake.SetDirection(Up)
+	if g.window.JustPressed(g.controls[stepAction]) {
+		g.controller.QueueStep()
 	}
-	if g.txt != nil {
-		g.updateCount.Tick(t)
+	if g.window.JustPressed(g.controls[slowerAction]) {
+		g.controller.SetTimeScale(math.Max(g.controller.TimeScale()/2, minTimeScale))
 	}
-
-	if snake2 != nil && g.window.Pressed(pixelgl.KeyA) {
-		snake2.SetDirection(Left)
+	if g.window.JustPressed(g.controls[fasterAction]) {
+		g.controller.SetTimeScale(math.Min(g.controller.TimeScale()*2, maxTimeScale))
 	}
-	if snake2 != nil && g.window.Pressed(pixelgl.KeyD) {
-		snake2.SetDirection(Right)
+	if g.window.JustPressed(g.controls[normalSpeedAction]) {
+		g.controller.SetTimeScale(1)
 	}
-	if snake2 != nil && g.window.Pressed(pixelgl.KeyS) {
-		snake2.SetDirection(Down)
+	if g.window.JustPressed(g.controls[statsAction]) {
+		g.showStats = !g.showStats
 	}
-	if snake2 != nil && g.window.Pressed(pixelgl.KeyW) {
-		snake2.SetDirection(Up)
+	if g.window.JustPressed(g.controls[restartAction]) {
+		g.restartLock.Lock()
+		g.restart = true
+		g.restartLock.Unlock()
 	}
+	if g.window.JustPressed(g.controls[screenshotAction]) {
+		_, err := saveScreenshot(g.window)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to save screenshot: %v\n", err.Error())
+		}
+	}
+}
+
+func (g *Game) Integrate(currentState sim.WorldState, t float64, deltaT float64) sim.WorldState {
+	g.scheduler.Update(deltaT)
+	return g.world.State()
+}
+
+// applyInput turns the snakes the way their controllers want them to go, and
+// starts a new game if restart was pressed once the game is over.
+func (g *Game) applyInput(t float64, _ float64) {
+	g.restartLock.Lock()
+	restart := g.restart
+	g.restart = false
+	g.restartLock.Unlock()
 
-	snake.Tick(t, deltaT)
-	if snake2 != nil {
-		snake2.Tick(t, deltaT)
+	if restart && g.world.Over() {
+		g.world.Restart()
 	}
-	return snakes
+	g.world.Steer(t)
+}
+
+func (g *Game) moveSnakes(t float64, deltaT float64) {
+	g.world.Step(t, deltaT)
+}
+
+// itemPlaced gives every new item a timer, which moves it if it's still there
+// when its lifetime is up.
+func (g *Game) itemPlaced(_ events.ItemPlaced) {
+	g.scheduler.Cancel(g.itemTimer)
+	g.itemTimer = g.scheduler.After(g.itemLifetime, g.expireItem)
+}
+
+func (g *Game) expireItem(_ float64) {
+	g.world.RelocateItem()
 }
+
 func ttfFromBytesMust(b []byte, size float64) font.Face {
 	ttf, err := truetype.Parse(b)
 	if err != nil {
@@ -239,63 +571,146 @@ func ttfFromBytesMust(b []byte, size float64) font.Face {
 	})
 }
 
-func (g *Game) Render(state interface{}, t float64, alpha float64) {
-	g.window.Clear(colornames.Mediumaquamarine)
+// drawStats overlays the frame and tick telemetry on the board.
+func (g *Game) drawStats() {
+	summary := g.stats.Summary()
 
-	g.playingBoard.Draw(g.window)
+	g.txt.Clear()
+	g.txt.WriteString(fmt.Sprintf("FPS :%4.2f, UPS: %4.2f", summary.FrameRate, summary.TickRate))
+	if g.controller != nil {
+		if g.controller.Paused() {
+			g.txt.WriteString(", PAUSED")
+		}
+		if scale := g.controller.TimeScale(); scale != 1 {
+			g.txt.WriteString(fmt.Sprintf(", x%g", scale))
+		}
+	}
+	g.txt.WriteString("\n")
+	writePercentiles(g.txt, "frame", summary.Frames)
+	writePercentiles(g.txt, "tick", summary.Ticks)
+	writePercentiles(g.txt, "backlog", summary.Backlog)
+	g.txt.WriteString(fmt.Sprintf("dropped ticks: %d\n", summary.DroppedTicks))
+	g.txt.WriteString(g.session.String())
+	g.txt.Draw(g.window, pixel.IM)
+}
 
-	g.tracker.Paint().Draw(g.window)
-	snakes := state.([]*Snake)
-	for index, s := range snakes {
-		s.Paint().Draw(g.window)
-		g.playerText[index].Clear()
-		g.playerText[index].WriteString(fmt.Sprintf("P%d: %d", index+1, s.score))
-		g.playerText[index].Draw(g.window, pixel.IM)
+// placePlayerText moves player index's text to its spot. Players fill a row
+// above the board before starting another below it, with at least two to a
+// row. The first in a row lines up with the left of the board, the last with
+// the right, and any others are centred in between.
+func (g *Game) placePlayerText(t *text.Text, index int, players int, str string) {
+	perRow := int(math.Max(2, math.Ceil(float64(players)/2)))
+	row := index / perRow
+	col := index % perRow
+	inRow := perRow
+	if left := players - row*perRow; left < inRow {
+		inRow = left
 	}
-	if g.txt != nil {
-		g.frameCount.Tick(t)
-		g.txt.Clear()
-		g.txt.WriteString(fmt.Sprintf("FPS :%4.2f, UPS: %4.2f", g.frameCount.GetRate(), g.updateCount.GetRate()))
-		g.txt.Draw(g.window, pixel.IM)
+
+	w := t.BoundsOf(str).W()
+	slot := g.hudBounds.W() / float64(inRow)
+	x := g.hudBounds.Min.X + slot*float64(col) + (slot-w)/2
+	switch {
+	case col == 0:
+		x = g.hudBounds.Min.X
+	case col == inRow-1:
+		x = g.hudBounds.Max.X - w
 	}
-	g.window.Update()
+	y := g.hudBounds.Max.Y
+	if row > 0 {
+		y = g.hudBounds.Min.Y
+	}
+	t.Orig = pixel.V(x, y)
 }
 
-type Counter struct {
-	maxSamples int
-	tickIndex  int
-	tickSum    float64
-	tickList   []float64
-	lastTime   float64
+// drawGameOver shows who won, if anybody did, and everyone's final score in
+// the middle of the window.
+func (g *Game) drawGameOver(state sim.WorldState) {
+	title := "GAME OVER"
+	if state.Won {
+		title = fmt.Sprintf("%s WINS!", g.names[state.Winner])
+	}
+	lines := []string{title, ""}
+	for index, s := range state.Snakes {
+		lines = append(lines, fmt.Sprintf("%s: %d", g.names[index], s.Score))
+	}
+	lines = append(lines, "", fmt.Sprintf("press %s to play again", buttonName(g.controls[restartAction])))
+
+	center := g.window.Bounds().Center()
+	g.banner.Orig = pixel.V(center.X, center.Y+g.banner.LineHeight*float64(len(lines))/2)
+	g.banner.Clear()
+	for _, line := range lines {
+		g.banner.Dot.X -= g.banner.BoundsOf(line).W() / 2
+		g.banner.WriteString(line + "\n")
+	}
+	g.banner.Draw(g.window, pixel.IM)
+}
 
-	rate float64
+func writePercentiles(txt *text.Text, name string, p metrics.Percentiles) {
+	txt.WriteString(fmt.Sprintf("%s p50/p95/p99: %.2f/%.2f/%.2f ms\n", name,
+		p.P50.Seconds()*1000, p.P95.Seconds()*1000, p.P99.Seconds()*1000))
 }
 
-func NewCounter(maxSamples int) *Counter {
-	return &Counter{
-		maxSamples: maxSamples,
-		tickList:   make([]float64, maxSamples),
+func (g *Game) Render(previous sim.WorldState, current sim.WorldState, t float64, alpha float64) {
+	if g.remap.open {
+		g.window.Clear(colornames.Cornsilk)
+		g.remap.draw(g.window)
+		g.window.Update()
+		g.handleControls()
+		return
 	}
-}
+	g.window.Clear(colornames.Mediumaquamarine)
+
+	g.playingBoard.Draw(g.window)
 
-func (c *Counter) Tick(currentT float64) float64 {
-	delta := currentT - c.lastTime
-	c.lastTime = currentT
-
-	c.tickSum -= c.tickList[c.tickIndex]
-	c.tickSum += delta
-	c.tickList[c.tickIndex] = delta
-	// c.tickIndex++
-	// if c.tickIndex >= c.maxSamples {
-	// 	c.tickIndex = 0
-	// }
-	c.tickIndex = (c.tickIndex + 1) % c.maxSamples
-	c.rate = float64(c.maxSamples) / c.tickSum
-	return c.rate
+	g.item.paint(current.Item).Draw(g.window)
+	for index, s := range current.Snakes {
+		g.renderers[index].paintBetween(previous.Snakes[index], s, alpha).Draw(g.window)
+	}
+	if g.frame != nil {
+		g.frame.Draw(g.window)
+	}
+	for index, s := range current.Snakes {
+		t := g.playerText[index]
+		str := fmt.Sprintf("%s: %d  lives %d  lv %d", g.names[index], g.hud.score(index), s.Lives, g.hud.level(index))
+		g.placePlayerText(t, index, len(current.Snakes), str)
+		t.Clear()
+		t.WriteString(str)
+		t.Draw(g.window, pixel.IM)
+	}
+	if current.Over {
+		g.drawGameOver(current)
+	}
+	if g.showStats {
+		g.drawStats()
+	}
+	g.window.Update()
+	g.sampleInput()
+	g.handleControls()
 }
 
-func (c *Counter) GetRate() float64 {
-	return c.rate
+// NewBoardFrame covers up everything outside of the playing area, so that
+// snakes going over the edge of a board that wraps don't show outside of it.
+func NewBoardFrame(windowWidth float64, windowHeight float64, boardWidth float64, boardHeight float64, buffer float64, borderWidth float64) *imdraw.IMDraw {
+	frame := imdraw.New(nil)
+
+	frame.Color = colornames.Mediumaquamarine
+	frame.EndShape = imdraw.SharpEndShape
+	frame.Push(pixel.V(0, 0), pixel.V(windowWidth, buffer))
+	frame.Rectangle(0)
+	frame.Push(pixel.V(0, buffer+boardHeight), pixel.V(windowWidth, windowHeight))
+	frame.Rectangle(0)
+	frame.Push(pixel.V(0, 0), pixel.V(buffer, windowHeight))
+	frame.Rectangle(0)
+	frame.Push(pixel.V(buffer+boardWidth, 0), pixel.V(windowWidth, windowHeight))
+	frame.Rectangle(0)
+
+	// only the outside half of the border, the same as NewPlayingBoard shows.
+	frame.Color = colornames.Black
+	frame.Push(pixel.V(buffer-borderWidth/2, buffer-borderWidth/2), pixel.V(buffer+boardWidth+borderWidth/2, buffer+boardHeight+borderWidth/2))
+	frame.Rectangle(borderWidth)
+
+	return frame
 }
 
 // NewPlayingBoard highlights the playing area with a background and border.
@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	smallestBucket = 10 * time.Microsecond
+	largestBucket  = 10 * time.Second
+	bucketGrowth   = 1.2
+)
+
+// Histogram counts durations in exponentially growing buckets, from 10µs up
+// to 10s. Anything larger ends up in the last bucket.
+type Histogram struct {
+	bounds []time.Duration
+	counts []uint64
+
+	count uint64
+	sum   time.Duration
+	min   time.Duration
+	max   time.Duration
+}
+
+// NewHistogram creates an empty Histogram.
+func NewHistogram() *Histogram {
+	var bounds []time.Duration
+	for b := float64(smallestBucket); ; b *= bucketGrowth {
+		bounds = append(bounds, time.Duration(b))
+		if time.Duration(b) >= largestBucket {
+			break
+		}
+	}
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)),
+	}
+}
+
+// Record adds a single duration to the histogram.
+func (h *Histogram) Record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	i := h.bucket(d)
+	h.counts[i]++
+
+	if h.count == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.count++
+	h.sum += d
+}
+
+func (h *Histogram) bucket(d time.Duration) int {
+	if d <= h.bounds[0] {
+		return 0
+	}
+	i := int(math.Ceil(math.Log(float64(d)/float64(smallestBucket)) / math.Log(bucketGrowth)))
+	if i >= len(h.bounds) {
+		return len(h.bounds) - 1
+	}
+	// make up for any rounding in the logs.
+	for i > 0 && d <= h.bounds[i-1] {
+		i--
+	}
+	for i < len(h.bounds)-1 && d > h.bounds[i] {
+		i++
+	}
+	return i
+}
+
+// Count returns the number of durations recorded.
+func (h *Histogram) Count() uint64 {
+	return h.count
+}
+
+// Min returns the smallest duration recorded.
+func (h *Histogram) Min() time.Duration {
+	return h.min
+}
+
+// Max returns the largest duration recorded.
+func (h *Histogram) Max() time.Duration {
+	return h.max
+}
+
+// Mean returns the average of the durations recorded.
+func (h *Histogram) Mean() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.count)
+}
+
+// Quantile estimates the duration that q of the recorded durations are at or
+// below, where q is between 0 and 1. For example, Quantile(0.95) is the p95.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return h.min
+	}
+	if q >= 1 {
+		return h.max
+	}
+
+	rank := q * float64(h.count)
+	var seen float64
+	for i, c := range h.counts {
+		if c == 0 {
+			continue
+		}
+		if seen+float64(c) < rank {
+			seen += float64(c)
+			continue
+		}
+		// assume the durations are spread evenly through the bucket, but
+		// keep the answer within what was actually recorded.
+		lower := time.Duration(0)
+		if i > 0 {
+			lower = h.bounds[i-1]
+		}
+		upper := h.bounds[i]
+		if lower < h.min {
+			lower = h.min
+		}
+		if upper > h.max {
+			upper = h.max
+		}
+		fraction := (rank - seen) / float64(c)
+		return lower + time.Duration(fraction*float64(upper-lower))
+	}
+	return h.max
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogramEmpty(t *testing.T) {
+	h := NewHistogram()
+	for _, q := range []float64{0, 0.5, 1} {
+		if got := h.Quantile(q); got != 0 {
+			t.Errorf("Quantile(%v) = %v, want 0", q, got)
+		}
+	}
+	if h.Mean() != 0 {
+		t.Errorf("Mean() = %v, want 0", h.Mean())
+	}
+}
+
+func TestHistogramSingleDuration(t *testing.T) {
+	h := NewHistogram()
+	h.Record(3 * time.Millisecond)
+
+	// with only one duration, every quantile is it.
+	for _, q := range []float64{0, 0.01, 0.5, 0.99, 1} {
+		if got := h.Quantile(q); got != 3*time.Millisecond {
+			t.Errorf("Quantile(%v) = %v, want 3ms", q, got)
+		}
+	}
+}
+
+func TestHistogramQuantiles(t *testing.T) {
+	h := NewHistogram()
+	for i := 1; i <= 1000; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	if h.Count() != 1000 {
+		t.Errorf("Count() = %d, want 1000", h.Count())
+	}
+	if h.Min() != time.Millisecond || h.Max() != time.Second {
+		t.Errorf("Min(), Max() = %v, %v, want 1ms, 1s", h.Min(), h.Max())
+	}
+	if want := 500500 * time.Microsecond; h.Mean() != want {
+		t.Errorf("Mean() = %v, want %v", h.Mean(), want)
+	}
+
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{q: 0, want: time.Millisecond},
+		{q: 0.1, want: 100 * time.Millisecond},
+		{q: 0.5, want: 500 * time.Millisecond},
+		{q: 0.95, want: 950 * time.Millisecond},
+		{q: 0.99, want: 990 * time.Millisecond},
+		{q: 1, want: time.Second},
+	}
+	for _, test := range tests {
+		got := h.Quantile(test.q)
+		// a bucket is a fifth wider than the one before, so an estimate
+		// can't be out by more than that.
+		if low, high := test.want*5/6, test.want*6/5; got < low || got > high {
+			t.Errorf("Quantile(%v) = %v, want %v give or take a bucket", test.q, got, test.want)
+		}
+	}
+
+	// quantiles never go down as q goes up.
+	last := time.Duration(0)
+	for q := 0.0; q <= 1; q += 0.01 {
+		got := h.Quantile(q)
+		if got < last {
+			t.Errorf("Quantile(%v) = %v, less than %v before it", q, got, last)
+		}
+		last = got
+	}
+}
+
+func TestHistogramOutOfRange(t *testing.T) {
+	h := NewHistogram()
+	h.Record(-time.Second)
+	h.Record(time.Minute)
+
+	if h.Min() != 0 {
+		t.Errorf("Min() = %v, want 0", h.Min())
+	}
+	if h.Max() != time.Minute {
+		t.Errorf("Max() = %v, want 1m", h.Max())
+	}
+	// anything over the largest bucket is still counted in it.
+	if got := h.counts[len(h.counts)-1]; got != 1 {
+		t.Errorf("last bucket has %d durations, want 1", got)
+	}
+	if got := h.Quantile(0.99); got < largestBucket || got > time.Minute {
+		t.Errorf("Quantile(0.99) = %v, want between %v and 1m", got, largestBucket)
+	}
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import "time"
+
+// rate is a rolling average of how often something happens per second, over
+// the last maxSamples times.
+type rate struct {
+	maxSamples int
+	samples    int
+	index      int
+	sum        time.Duration
+	intervals  []time.Duration
+	last       time.Time
+
+	rate float64
+}
+
+func newRate(maxSamples int) *rate {
+	return &rate{
+		maxSamples: maxSamples,
+		intervals:  make([]time.Duration, maxSamples),
+	}
+}
+
+func (r *rate) tick(at time.Time) {
+	if r.last.IsZero() {
+		r.last = at
+		return
+	}
+	interval := at.Sub(r.last)
+	r.last = at
+
+	r.sum -= r.intervals[r.index]
+	r.sum += interval
+	r.intervals[r.index] = interval
+	r.index = (r.index + 1) % r.maxSamples
+	if r.samples < r.maxSamples {
+		r.samples++
+	}
+	if r.sum > 0 {
+		r.rate = float64(r.samples) / r.sum.Seconds()
+	}
+}
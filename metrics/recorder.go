@@ -0,0 +1,150 @@
+// Package metrics records how long the frames and ticks of a game loop take,
+// so performance can be watched while playing and compared between releases.
+package metrics
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const rateSamples = 100
+
+// Recorder collects frame and tick telemetry from a gameloop.Loop. It is safe
+// to use from multiple goroutines.
+type Recorder struct {
+	lock sync.Mutex
+
+	frames       *Histogram
+	ticks        *Histogram
+	backlog      *Histogram
+	droppedTicks uint64
+
+	frameRate *rate
+	tickRate  *rate
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		frames:    NewHistogram(),
+		ticks:     NewHistogram(),
+		backlog:   NewHistogram(),
+		frameRate: newRate(rateSamples),
+		tickRate:  newRate(rateSamples),
+	}
+}
+
+// FrameRendered records a frame that was rendered at the given time,
+// frameTime after the one before it.
+func (r *Recorder) FrameRendered(at time.Time, frameTime time.Duration) {
+	r.lock.Lock()
+	r.frames.Record(frameTime)
+	r.frameRate.tick(at)
+	r.lock.Unlock()
+}
+
+// TickIntegrated records a tick that was integrated at the given time and
+// took d to integrate.
+func (r *Recorder) TickIntegrated(at time.Time, d time.Duration) {
+	r.lock.Lock()
+	r.ticks.Record(d)
+	r.tickRate.tick(at)
+	r.lock.Unlock()
+}
+
+// TicksDropped records ticks that were never integrated because the time
+// between frames was capped.
+func (r *Recorder) TicksDropped(n int) {
+	r.lock.Lock()
+	r.droppedTicks += uint64(n)
+	r.lock.Unlock()
+}
+
+// Backlog records how much time was left waiting to be integrated after the
+// due ticks were.
+func (r *Recorder) Backlog(d time.Duration) {
+	r.lock.Lock()
+	r.backlog.Record(d)
+	r.lock.Unlock()
+}
+
+// Summary is a point in time view of the telemetry.
+type Summary struct {
+	FrameRate    float64
+	TickRate     float64
+	Frames       Percentiles
+	Ticks        Percentiles
+	Backlog      Percentiles
+	DroppedTicks uint64
+}
+
+// Percentiles are the common percentiles of a histogram.
+type Percentiles struct {
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+}
+
+func percentiles(h *Histogram) Percentiles {
+	return Percentiles{
+		P50: h.Quantile(0.50),
+		P95: h.Quantile(0.95),
+		P99: h.Quantile(0.99),
+	}
+}
+
+// Summary returns the current rates, percentiles and dropped tick count.
+func (r *Recorder) Summary() Summary {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return Summary{
+		FrameRate:    r.frameRate.rate,
+		TickRate:     r.tickRate.rate,
+		Frames:       percentiles(r.frames),
+		Ticks:        percentiles(r.ticks),
+		Backlog:      percentiles(r.backlog),
+		DroppedTicks: r.droppedTicks,
+	}
+}
+
+// WriteCSV writes one row per histogram with its count, min, mean,
+// percentiles and max in milliseconds, followed by a row with the number of
+// dropped ticks.
+func (r *Recorder) WriteCSV(w io.Writer) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cw := csv.NewWriter(w)
+	rows := [][]string{
+		{"metric", "count", "min_ms", "mean_ms", "p50_ms", "p95_ms", "p99_ms", "max_ms"},
+		histogramRow("frame", r.frames),
+		histogramRow("tick", r.ticks),
+		histogramRow("backlog", r.backlog),
+		{"dropped_ticks", strconv.FormatUint(r.droppedTicks, 10), "", "", "", "", "", ""},
+	}
+	err := cw.WriteAll(rows)
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func histogramRow(name string, h *Histogram) []string {
+	return []string{
+		name,
+		strconv.FormatUint(h.Count(), 10),
+		milliseconds(h.Min()),
+		milliseconds(h.Mean()),
+		milliseconds(h.Quantile(0.50)),
+		milliseconds(h.Quantile(0.95)),
+		milliseconds(h.Quantile(0.99)),
+		milliseconds(h.Max()),
+	}
+}
+
+func milliseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+)
+
+// remapScreen lets players change their keys in the game. A slot is picked
+// with the arrow keys and Enter, and then bound to whatever key is pressed
+// next. Binding a key that's already in use swaps it with the slot it was in,
+// so no two slots ever share a key. It has to be used from the main thread.
+type remapScreen struct {
+	slots    []keySlot
+	txt      *text.Text
+	open     bool
+	selected int
+	// the first slot shown, when they don't all fit.
+	top int
+	// set once Enter has been pressed on the selected slot.
+	waiting bool
+	message string
+}
+
+func newRemapScreen(slots []keySlot, txt *text.Text) *remapScreen {
+	return &remapScreen{
+		slots: slots,
+		txt:   txt,
+	}
+}
+
+// show opens the screen with the first slot selected.
+func (r *remapScreen) show() {
+	r.open = true
+	r.selected = 0
+	r.top = 0
+	r.waiting = false
+	r.message = ""
+}
+
+// update handles the keys just pressed in win, and reports whether the screen
+// is still open afterwards.
+func (r *remapScreen) update(win *pixelgl.Window) bool {
+	if r.waiting {
+		if win.JustPressed(pixelgl.KeyEscape) {
+			r.waiting = false
+			r.message = ""
+			return true
+		}
+		for _, b := range buttons {
+			if win.JustPressed(b) {
+				r.bind(b)
+				break
+			}
+		}
+		return true
+	}
+
+	switch {
+	case win.JustPressed(pixelgl.KeyEscape):
+		r.open = false
+	case win.JustPressed(pixelgl.KeyUp):
+		r.selected = (r.selected + len(r.slots) - 1) % len(r.slots)
+	case win.JustPressed(pixelgl.KeyDown):
+		r.selected = (r.selected + 1) % len(r.slots)
+	case win.JustPressed(pixelgl.KeyEnter):
+		r.waiting = true
+		r.message = fmt.Sprintf("press a key for %s", r.slots[r.selected].name)
+	}
+	return r.open
+}
+
+// bind puts b in the selected slot, swapping it with the slot that had it.
+func (r *remapScreen) bind(b pixelgl.Button) {
+	slot := r.slots[r.selected]
+	r.waiting = false
+	r.message = ""
+	for _, other := range r.slots {
+		if other.button != slot.button && *other.button == b {
+			*other.button = *slot.button
+			r.message = fmt.Sprintf("swapped with %s", other.name)
+		}
+	}
+	*slot.button = b
+}
+
+// draw shows the slots and their keys in win, as many as fit, scrolled so the
+// selected one can be seen.
+func (r *remapScreen) draw(win *pixelgl.Window) {
+	header := []string{"REMAP KEYS", "up/down picks, enter changes, esc closes", ""}
+	footer := []string{"", r.message}
+
+	bounds := win.Bounds()
+	rows := int(bounds.H()/r.txt.LineHeight) - len(header) - len(footer) - 1
+	if rows < 1 {
+		rows = 1
+	}
+	if r.selected < r.top {
+		r.top = r.selected
+	}
+	if r.selected >= r.top+rows {
+		r.top = r.selected - rows + 1
+	}
+
+	lines := append([]string(nil), header...)
+	for i := r.top; i < len(r.slots) && i < r.top+rows; i++ {
+		marker := "  "
+		if i == r.selected {
+			marker = "> "
+		}
+		key := buttonName(*r.slots[i].button)
+		if i == r.selected && r.waiting {
+			key = "?"
+		}
+		lines = append(lines, fmt.Sprintf("%s%s: %s", marker, r.slots[i].name, key))
+	}
+	lines = append(lines, footer...)
+
+	r.txt.Orig = pixel.V(bounds.Min.X+r.txt.LineHeight, bounds.Max.Y-r.txt.LineHeight)
+	r.txt.Clear()
+	for _, line := range lines {
+		r.txt.WriteString(line + "\n")
+	}
+	r.txt.Draw(win, pixel.IM)
+}
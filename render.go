@@ -0,0 +1,200 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+// snakeRenderer draws snapshots of a sim.Snake.
+type snakeRenderer struct {
+	squareSize float64
+	buffer     float64
+	taperTo    float64
+	colors     []color.Color
+	movement   sim.Movement
+	// edges only matter if wrap is set, in which case pieces of the snake
+	// going over an edge are drawn at the opposite edge too.
+	edges sim.Edges
+	wrap  bool
+}
+
+// paintBetween draws the snake part way between two snapshots. alpha is how
+// far along from previous to current the drawing should be.
+func (r snakeRenderer) paintBetween(previous sim.SnakeState, current sim.SnakeState, alpha float64) *imdraw.IMDraw {
+	if current.Invulnerable {
+		r.colors = faded(r.colors)
+	}
+	// don't slide across the board after a reset.
+	if previous.Resets != current.Resets {
+		return r.paint(current.Locations)
+	}
+	if r.movement == sim.Grid {
+		return r.paint(r.gridBetween(previous, current, alpha))
+	}
+
+	locations := make([]sim.Location, len(current.Locations))
+	for i, c := range current.Locations {
+		locations[i] = c
+		if i >= len(previous.Locations) {
+			continue
+		}
+		// every tick the head moves forward and each piece of the body moves
+		// into the spot of the one in front of it, so matching indexes are the
+		// same piece of the snake.
+		p := previous.Locations[i]
+		dx, dy := r.delta(p, c)
+		locations[i] = sim.NewLocation(
+			p.X()+dx*alpha,
+			p.Y()+dy*alpha,
+		)
+	}
+	return r.paint(locations)
+}
+
+// gridBetween works out where a Grid snake is part way between two snapshots.
+// A Grid snake only moves a whole square at a time, so rather than sliding
+// from one snapshot to the next, every piece slides towards the spot it moves
+// into next by however far the snake has got to its next square.
+func (r snakeRenderer) gridBetween(previous sim.SnakeState, current sim.SnakeState, alpha float64) []sim.Location {
+	moved := 0.0
+	if len(previous.Locations) > 0 && len(current.Locations) > 0 && !previous.Locations[0].Equal(current.Locations[0]) {
+		moved = 1
+	}
+	progress := previous.Progress + (current.Progress+moved-previous.Progress)*alpha
+
+	from := previous
+	if progress >= 1 {
+		from = current
+		progress -= moved
+	}
+
+	locations := make([]sim.Location, len(from.Locations))
+	for i, l := range from.Locations {
+		// the head moves on to the next square and the rest of the snake
+		// follows it.
+		next := l.Next(from.Direction)
+		if i > 0 {
+			next = from.Locations[i-1]
+		}
+		dx, dy := r.delta(l, next)
+		locations[i] = sim.NewLocation(
+			l.X()+dx*progress,
+			l.Y()+dy*progress,
+		)
+	}
+	return locations
+}
+
+// delta is how far it is from one location to another, going across the
+// edges of the board if they wrap and that's shorter.
+func (r snakeRenderer) delta(from sim.Location, to sim.Location) (float64, float64) {
+	if r.wrap {
+		return r.edges.Delta(from, to)
+	}
+	return to.X() - from.X(), to.Y() - from.Y()
+}
+
+// images returns everywhere a piece of the snake at l has to be drawn. On a
+// board that wraps, a piece that pokes out over one edge pokes back in at the
+// opposite edge.
+func (r snakeRenderer) images(l sim.Location) []sim.Location {
+	if !r.wrap {
+		return []sim.Location{l}
+	}
+	l = r.edges.Wrap(l)
+
+	xs := []float64{l.X()}
+	if l.X() > r.edges.Right-1 {
+		xs = append(xs, l.X()-(r.edges.Right-r.edges.Left))
+	}
+	ys := []float64{l.Y()}
+	if l.Y() > r.edges.Top-1 {
+		ys = append(ys, l.Y()-(r.edges.Top-r.edges.Bottom))
+	}
+
+	images := make([]sim.Location, 0, len(xs)*len(ys))
+	for _, x := range xs {
+		for _, y := range ys {
+			images = append(images, sim.NewLocation(x, y))
+		}
+	}
+	return images
+}
+
+// paint draws a snake with its head at the front of locations.
+func (r snakeRenderer) paint(locations []sim.Location) *imdraw.IMDraw {
+	newDrawing := imdraw.New(nil)
+	newDrawing.EndShape = imdraw.SharpEndShape
+
+	colors := r.colors
+	if len(colors) == 0 {
+		colors = []color.Color{color.RGBA{0x00, 0x00, 0x00, 0xff}}
+	}
+	ss := r.squareSize
+	b := r.buffer
+
+	sLen := float64(len(locations))
+
+	// a Continuous snake has several locations to a square, so only every
+	// other one needs drawing.
+	step := 2
+	if r.movement == sim.Grid {
+		step = 1
+	}
+
+	k := len(locations) - 1
+	i := int(math.Mod(math.Round(sLen/float64(step)), float64(len(colors))))
+	radius := r.taperTo / 2.0
+	rDelta := float64(step) * (r.squareSize - r.taperTo) / (2 * sLen)
+	for k >= 0 {
+		l := locations[k]
+
+		if i < 0 {
+			i = len(colors) - 1
+		}
+		newDrawing.Color = colors[i]
+		// newDrawing.Push(pixel.Vec{X: s.buffer + l.X()*s.squareSize, Y: s.buffer + l.Y()*s.squareSize}, pixel.Vec{X: s.buffer + (l.X() * s.squareSize) + s.squareSize, Y: s.buffer + (l.Y() * s.squareSize) + s.squareSize})
+		for _, l := range r.images(l) {
+			newDrawing.Push(pixel.Vec{X: b + l.X()*ss + ss/2, Y: b + l.Y()*ss + ss/2})
+			newDrawing.Circle(radius, 0)
+		}
+		k -= step
+		radius += rDelta
+		i--
+	}
+	return newDrawing
+}
+
+// faded makes colors see through, for drawing a snake that can't be run into.
+func faded(colors []color.Color) []color.Color {
+	f := make([]color.Color, len(colors))
+	for i, c := range colors {
+		f[i] = pixel.ToRGBA(c).Mul(pixel.Alpha(0.4))
+	}
+	return f
+}
+
+// itemRenderer draws the item the snakes are after.
+type itemRenderer struct {
+	squareSize float64
+	buffer     float64
+	color      color.Color
+}
+
+func (r itemRenderer) paint(l sim.Location) *imdraw.IMDraw {
+	newDrawing := imdraw.New(nil)
+	newDrawing.Color = r.color
+	newDrawing.EndShape = imdraw.SharpEndShape
+
+	floatX := l.X()
+	floatY := l.Y()
+
+	newDrawing.Push(pixel.Vec{X: r.buffer + floatX*r.squareSize, Y: r.buffer + floatY*r.squareSize}, pixel.Vec{X: r.buffer + (floatX)*r.squareSize + r.squareSize, Y: r.buffer + (floatY)*r.squareSize + r.squareSize})
+	newDrawing.Rectangle(0)
+
+	return newDrawing
+}
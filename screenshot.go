@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"time"
+
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// saveScreenshot writes the last frame shown in win to a PNG in the working
+// directory, named for when it was taken, and returns the file's name.
+func saveScreenshot(win *pixelgl.Window) (string, error) {
+	canvas := win.Canvas()
+	pixels := canvas.Pixels()
+	width := canvas.Texture().Width()
+	height := canvas.Texture().Height()
+
+	// the canvas starts at the bottom left, images at the top left.
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	row := width * 4
+	for y := 0; y < height; y++ {
+		copy(img.Pix[y*img.Stride:y*img.Stride+row], pixels[(height-1-y)*row:(height-y)*row])
+	}
+
+	name := fmt.Sprintf("snake-%s.png", time.Now().Format("20060102-150405.000"))
+	f, err := os.Create(name)
+	if err != nil {
+		return "", err
+	}
+	err = png.Encode(f, img)
+	if err != nil {
+		f.Close()
+		return "", err
+	}
+	return name, f.Close()
+}
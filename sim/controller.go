@@ -0,0 +1,120 @@
+package sim
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strings"
+	"sync"
+)
+
+// Controller drives a snake. Every input tick it's shown the world as it
+// stands and says which way it wants its snake to go next, or None to carry
+// on as it is. Any snake can be driven by any Controller.
+type Controller interface {
+	Intent(t float64, world WorldState) Direction
+}
+
+// Forfeiter is a Controller that can forfeit the game, like a bot that stops
+// answering in time. A World takes the snake of a controller that has
+// forfeited out of the game for good.
+type Forfeiter interface {
+	Controller
+	// Forfeited returns why the controller forfeited, or nil if it hasn't.
+	Forfeited() error
+}
+
+// ControllerFunc lets an ordinary function be used as a Controller.
+type ControllerFunc func(t float64, world WorldState) Direction
+
+func (f ControllerFunc) Intent(t float64, world WorldState) Direction {
+	return f(t, world)
+}
+
+// Queue is a Controller for directions that come from somewhere else, like a
+// keyboard or a network connection. Directions pushed to it are handed out in
+// the order they were pushed, one per tick, so quick turns in between ticks
+// aren't lost. Like a snake's turns, only so many can be waiting at once, so
+// a burst of them can't keep the snake turning long after it's over. It's
+// safe for concurrent use.
+type Queue struct {
+	lock       sync.Mutex
+	size       int
+	directions []Direction
+}
+
+// NewQueue creates an empty Queue that holds up to size directions, which is
+// usually the TurnBuffer of the snake it drives. It's DefaultTurnBuffer if
+// it's not positive.
+func NewQueue(size int) *Queue {
+	if size <= 0 {
+		size = DefaultTurnBuffer
+	}
+	return &Queue{size: size}
+}
+
+// Push adds d to the end of the queue. None is ignored, and so is d if the
+// queue is already full.
+func (q *Queue) Push(d Direction) {
+	if d == None {
+		return
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if len(q.directions) >= q.size {
+		return
+	}
+	q.directions = append(q.directions, d)
+}
+
+func (q *Queue) Intent(_ float64, _ WorldState) Direction {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if len(q.directions) == 0 {
+		return None
+	}
+	d := q.directions[0]
+	q.directions = append(q.directions[:0], q.directions[1:]...)
+	return d
+}
+
+// ReadDirections pushes the directions named on each line read from r to q,
+// until r runs out or fails, so a snake can be driven over a network
+// connection. Lines that don't name a direction are skipped. It returns nil
+// once r runs out.
+func ReadDirections(r io.Reader, q *Queue) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		q.Push(GetDirection(strings.TrimSpace(scanner.Text())))
+	}
+	return scanner.Err()
+}
+
+// Square returns the square l is in.
+func Square(l Location) Location {
+	return Location{x: math.Floor(l.x), y: math.Floor(l.y)}
+}
+
+// Blocked reports whether the square l is in is off the board, or has a snake
+// in it that can be run into. On a board that wraps, squares off one edge are
+// back on at the opposite edge.
+func Blocked(world WorldState, l Location) bool {
+	l = Square(l)
+	if world.Wrap {
+		l = world.Edges.Wrap(l)
+	}
+	if !world.Edges.Contains(l) {
+		return true
+	}
+	for _, s := range world.Snakes {
+		if s.Invulnerable {
+			continue
+		}
+		for _, piece := range s.Locations {
+			if Square(piece) == l {
+				return true
+			}
+		}
+	}
+	return false
+}
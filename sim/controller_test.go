@@ -0,0 +1,37 @@
+package sim
+
+import "testing"
+
+func TestQueueIsBounded(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		want []Direction
+	}{
+		{name: "two", size: 2, want: []Direction{Up, Left}},
+		{name: "default", size: 0, want: []Direction{Up, Left, Down}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := NewQueue(test.size)
+			for _, d := range []Direction{Up, None, Left, Down, Right} {
+				q.Push(d)
+			}
+			for i, want := range test.want {
+				if got := q.Intent(0, WorldState{}); got != want {
+					t.Errorf("intent %d = %v, want %v", i, got, want)
+				}
+			}
+			if got := q.Intent(0, WorldState{}); got != None {
+				t.Errorf("intent once the queue is empty = %v, want None", got)
+			}
+
+			// there's room again once directions have been handed out.
+			q.Push(Right)
+			if got := q.Intent(0, WorldState{}); got != Right {
+				t.Errorf("intent after emptying = %v, want Right", got)
+			}
+		})
+	}
+}
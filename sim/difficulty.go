@@ -0,0 +1,65 @@
+package sim
+
+import "strings"
+
+// Difficulty decides how fast a snake starts out and how it speeds up as it
+// levels up. A snake goes up a level every PointsPerLevel points, until it
+// reaches MaxLevel.
+type Difficulty struct {
+	// SpeedScale multiplies the snake's speed at every level.
+	SpeedScale float64
+	// PointsPerLevel is how many points it takes to go up a level. Snakes
+	// never level up if it's not positive.
+	PointsPerLevel int
+	// SpeedPerLevel is how much faster the snake gets with each level, as a
+	// fraction of its starting speed.
+	SpeedPerLevel float64
+	// GrowthPerLevel is how much more the snake grows by for each item it
+	// eats with each level.
+	GrowthPerLevel int
+	MaxLevel       int
+}
+
+var (
+	Easy   = Difficulty{SpeedScale: 0.8, PointsPerLevel: 10, SpeedPerLevel: 0.05, MaxLevel: 5}
+	Normal = Difficulty{SpeedScale: 1, PointsPerLevel: 5, SpeedPerLevel: 0.1, MaxLevel: 10}
+	Hard   = Difficulty{SpeedScale: 1.25, PointsPerLevel: 4, SpeedPerLevel: 0.12, GrowthPerLevel: 1, MaxLevel: 12}
+	Insane = Difficulty{SpeedScale: 1.6, PointsPerLevel: 3, SpeedPerLevel: 0.15, GrowthPerLevel: 2, MaxLevel: 15}
+)
+
+func GetDifficulty(d string) Difficulty {
+	ld := strings.ToLower(d)
+	switch ld {
+	case "easy":
+		return Easy
+	case "hard":
+		return Hard
+	case "insane":
+		return Insane
+	default:
+		return Normal
+	}
+}
+
+// Level returns the level a snake with score is at, starting from 1.
+func (d Difficulty) Level(score int) int {
+	if d.PointsPerLevel <= 0 {
+		return 1
+	}
+	level := 1 + score/d.PointsPerLevel
+	if d.MaxLevel > 0 && level > d.MaxLevel {
+		level = d.MaxLevel
+	}
+	return level
+}
+
+// Speed returns how fast a snake with the given starting speed goes at level.
+func (d Difficulty) Speed(speed float64, level int) float64 {
+	return speed * d.SpeedScale * (1 + d.SpeedPerLevel*float64(level-1))
+}
+
+// Growth returns how much a snake with the given starting growth grows by for
+// each item it eats at level.
+func (d Difficulty) Growth(growth int, level int) int {
+	return growth + d.GrowthPerLevel*(level-1)
+}
@@ -0,0 +1,25 @@
+package sim
+
+import "strings"
+
+// Movement is how a snake gets from one square to the next.
+type Movement int
+
+const (
+	// Continuous moves the snake a little every tick, turning once it's
+	// close enough to a square.
+	Continuous Movement = iota
+	// Grid moves the snake a whole square at a time, turning at every square
+	// it reaches.
+	Grid
+)
+
+func GetMovement(m string) Movement {
+	lm := strings.ToLower(m)
+	switch lm {
+	case "grid":
+		return Grid
+	default:
+		return Continuous
+	}
+}
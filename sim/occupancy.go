@@ -0,0 +1,166 @@
+package sim
+
+import "math/rand"
+
+// piece is one of the locations of a player's snake.
+type piece struct {
+	player   int
+	location Location
+}
+
+// Occupancy keeps track of which pieces of which snakes are in every square
+// of the board, so what's in a square can be found without going through
+// every snake. It also keeps a set of the squares nothing is in, so a free
+// square can be picked straight away however full the board gets. Snakes
+// keep it up to date as they move.
+type Occupancy struct {
+	edges  Edges
+	width  int
+	height int
+	cells  [][]piece
+
+	// free holds every square with nothing in it, and slots holds where each
+	// square is in free, or -1 if it isn't.
+	free  []int
+	slots []int
+}
+
+func NewOccupancy(edges Edges) *Occupancy {
+	e := edges
+	if edges.Right < edges.Left {
+		e.Right = edges.Left
+		e.Left = edges.Right
+	}
+	if edges.Top < edges.Bottom {
+		e.Top = edges.Bottom
+		e.Bottom = edges.Top
+	}
+
+	width := int(e.Right) - int(e.Left)
+	height := int(e.Top) - int(e.Bottom)
+	o := &Occupancy{
+		edges:  e,
+		width:  width,
+		height: height,
+		cells:  make([][]piece, width*height),
+		free:   make([]int, width*height),
+		slots:  make([]int, width*height),
+	}
+	for i := range o.free {
+		o.free[i] = i
+		o.slots[i] = i
+	}
+	return o
+}
+
+// Add puts a piece of player's snake at l.
+func (o *Occupancy) Add(player int, l Location) {
+	c, ok := o.cell(l)
+	if !ok {
+		return
+	}
+	if len(o.cells[c]) == 0 {
+		o.take(c)
+	}
+	o.cells[c] = append(o.cells[c], piece{player: player, location: l})
+}
+
+// Remove takes away a piece of player's snake that was added at l.
+func (o *Occupancy) Remove(player int, l Location) {
+	c, ok := o.cell(l)
+	if !ok {
+		return
+	}
+	pieces := o.cells[c]
+	for i, p := range pieces {
+		if p.player == player && p.location == l {
+			pieces[i] = pieces[len(pieces)-1]
+			o.cells[c] = pieces[:len(pieces)-1]
+			break
+		}
+	}
+	if len(o.cells[c]) == 0 {
+		o.release(c)
+	}
+}
+
+// Occupied reports whether any snake is in the square l is in.
+func (o *Occupancy) Occupied(l Location) bool {
+	c, ok := o.cell(l)
+	return ok && len(o.cells[c]) > 0
+}
+
+// Has reports whether any piece of player's snake is in the square l is in.
+func (o *Occupancy) Has(player int, l Location) bool {
+	return o.Count(player, l) > 0
+}
+
+// Count returns how many pieces of player's snake are in the square l is in.
+func (o *Occupancy) Count(player int, l Location) int {
+	c, ok := o.cell(l)
+	if !ok {
+		return 0
+	}
+	n := 0
+	for _, p := range o.cells[c] {
+		if p.player == player {
+			n++
+		}
+	}
+	return n
+}
+
+// Free returns how many squares have nothing in them.
+func (o *Occupancy) Free() int {
+	return len(o.free)
+}
+
+// RandomFree picks one of the squares with nothing in it using r. It reports
+// false if there aren't any.
+func (o *Occupancy) RandomFree(r *rand.Rand) (Location, bool) {
+	if len(o.free) == 0 {
+		return Location{}, false
+	}
+	c := o.free[r.Intn(len(o.free))]
+	return Location{
+		x: float64(c%o.width) + o.edges.Left,
+		y: float64(c/o.width) + o.edges.Bottom,
+	}, true
+}
+
+// pieces returns the pieces in the square l is in.
+func (o *Occupancy) pieces(l Location) []piece {
+	c, ok := o.cell(l)
+	if !ok {
+		return nil
+	}
+	return o.cells[c]
+}
+
+func (o *Occupancy) cell(l Location) (int, bool) {
+	if !o.edges.Contains(l) {
+		return 0, false
+	}
+	x := int(l.x) - int(o.edges.Left)
+	y := int(l.y) - int(o.edges.Bottom)
+	return y*o.width + x, true
+}
+
+// take removes c from the free squares by swapping the last one into its
+// place.
+func (o *Occupancy) take(c int) {
+	i := o.slots[c]
+	last := o.free[len(o.free)-1]
+	o.free[i] = last
+	o.slots[last] = i
+	o.free = o.free[:len(o.free)-1]
+	o.slots[c] = -1
+}
+
+func (o *Occupancy) release(c int) {
+	if o.slots[c] >= 0 {
+		return
+	}
+	o.slots[c] = len(o.free)
+	o.free = append(o.free, c)
+}
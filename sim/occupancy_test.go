@@ -0,0 +1,127 @@
+package sim
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// checkFree fails t unless the free squares o keeps are exactly the squares
+// with nothing in them, and slots says where each of them is.
+func checkFree(t *testing.T, o *Occupancy) {
+	t.Helper()
+	empty := 0
+	for c, pieces := range o.cells {
+		if len(pieces) > 0 {
+			if o.slots[c] != -1 {
+				t.Fatalf("square %d is occupied but has slot %d", c, o.slots[c])
+			}
+			continue
+		}
+		empty++
+		if i := o.slots[c]; i < 0 || i >= len(o.free) || o.free[i] != c {
+			t.Fatalf("square %d is empty but isn't in free at slot %d", c, i)
+		}
+	}
+	if len(o.free) != empty || o.Free() != empty {
+		t.Fatalf("Free() = %d, want %d", o.Free(), empty)
+	}
+}
+
+func TestOccupancyAddRemove(t *testing.T) {
+	o := NewOccupancy(Edges{Right: 3, Top: 2})
+	checkFree(t, o)
+	if o.Free() != 6 {
+		t.Fatalf("Free() = %d, want 6", o.Free())
+	}
+
+	// a continuous snake can have several pieces in one square.
+	a, b := NewLocation(1.2, 0.5), NewLocation(1.7, 0.5)
+	o.Add(0, a)
+	o.Add(0, b)
+	o.Add(1, b)
+	checkFree(t, o)
+	if o.Free() != 5 {
+		t.Errorf("Free() = %d, want 5", o.Free())
+	}
+	if n := o.Count(0, NewLocation(1, 0)); n != 2 {
+		t.Errorf("Count(0) = %d, want 2", n)
+	}
+	if !o.Has(1, a) {
+		t.Error("Has(1) = false, want true")
+	}
+
+	o.Remove(0, a)
+	o.Remove(1, b)
+	checkFree(t, o)
+	if !o.Occupied(a) {
+		t.Error("square is free with a piece still in it")
+	}
+	// taking away a piece that isn't there changes nothing.
+	o.Remove(1, b)
+	checkFree(t, o)
+	o.Remove(0, b)
+	checkFree(t, o)
+	if o.Occupied(a) || o.Free() != 6 {
+		t.Errorf("Occupied() = %v, Free() = %d, want an empty board", o.Occupied(a), o.Free())
+	}
+
+	// pieces off the board aren't kept track of.
+	o.Add(0, NewLocation(3, 0))
+	o.Add(0, NewLocation(-1, 1))
+	checkFree(t, o)
+	if o.Free() != 6 {
+		t.Errorf("Free() = %d, want 6", o.Free())
+	}
+}
+
+func TestOccupancyRandomMoves(t *testing.T) {
+	edges := Edges{Left: -2, Right: 5, Bottom: -1, Top: 3}
+	o := NewOccupancy(edges)
+	r := rand.New(rand.NewSource(1))
+	var added []piece
+	for i := 0; i < 2000; i++ {
+		if len(added) > 0 && r.Intn(2) == 0 {
+			j := r.Intn(len(added))
+			p := added[j]
+			added = append(added[:j], added[j+1:]...)
+			o.Remove(p.player, p.location)
+		} else {
+			p := piece{
+				player:   r.Intn(3),
+				location: NewLocation(float64(r.Intn(7)-2), float64(r.Intn(4)-1)),
+			}
+			added = append(added, p)
+			o.Add(p.player, p.location)
+		}
+		checkFree(t, o)
+
+		if l, ok := o.RandomFree(r); ok && (o.Occupied(l) || !edges.Contains(l)) {
+			t.Fatalf("RandomFree() = %v, which isn't a free square", l)
+		}
+	}
+}
+
+func TestOccupancyRandomFree(t *testing.T) {
+	o := NewOccupancy(Edges{Right: 2, Top: 2})
+	o.Add(0, NewLocation(0, 0))
+	o.Add(0, NewLocation(1, 1))
+
+	r := rand.New(rand.NewSource(1))
+	seen := make(map[Location]bool)
+	for i := 0; i < 100; i++ {
+		l, ok := o.RandomFree(r)
+		if !ok {
+			t.Fatal("RandomFree() found nowhere with two squares free")
+		}
+		seen[l] = true
+	}
+	if len(seen) != 2 || !seen[NewLocation(1, 0)] || !seen[NewLocation(0, 1)] {
+		t.Errorf("RandomFree() picked %v, want (1, 0) and (0, 1)", seen)
+	}
+
+	o.Add(1, NewLocation(1, 0))
+	o.Add(1, NewLocation(0, 1))
+	if l, ok := o.RandomFree(r); ok {
+		t.Errorf("RandomFree() = %v on a full board", l)
+	}
+}
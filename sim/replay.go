@@ -0,0 +1,156 @@
+package sim
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Recording is a Controller that passes on the intents of another, keeping
+// every one that isn't None so they can be played back with a Replay. It also
+// keeps the seed the game's items were placed with, since a game only plays
+// out the same way again if its items go to the same places.
+type Recording struct {
+	controller Controller
+	seed       int64
+
+	lock    sync.Mutex
+	tick    int
+	intents []recordedIntent
+}
+
+type recordedIntent struct {
+	tick      int
+	direction Direction
+}
+
+// Record starts recording the intents of c, in a game with items placed
+// using seed.
+func Record(c Controller, seed int64) *Recording {
+	return &Recording{controller: c, seed: seed}
+}
+
+func (r *Recording) Intent(t float64, world WorldState) Direction {
+	d := r.controller.Intent(t, world)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if d != None {
+		r.intents = append(r.intents, recordedIntent{r.tick, d})
+	}
+	r.tick++
+	return d
+}
+
+// Forfeited passes on why the recorded controller forfeited, if it can.
+func (r *Recording) Forfeited() error {
+	if f, ok := r.controller.(Forfeiter); ok {
+		return f.Forfeited()
+	}
+	return nil
+}
+
+// WriteTo writes the seed to w, as a line such as "seed 7", followed by the
+// intents recorded so far, one per line as the tick they were made on
+// followed by the direction, such as "42 up".
+func (r *Recording) WriteTo(w io.Writer) (int64, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	n, err := fmt.Fprintf(w, "seed %d\n", r.seed)
+	written := int64(n)
+	if err != nil {
+		return written, err
+	}
+	for _, i := range r.intents {
+		n, err := fmt.Fprintf(w, "%d %v\n", i.tick, i.direction)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// Replay is a Controller that plays back the intents written by a Recording,
+// each on the same tick it was recorded on. A game only plays out the same
+// way again if everything else in it does too.
+type Replay struct {
+	lock    sync.Mutex
+	tick    int
+	next    int
+	intents []recordedIntent
+
+	seed    int64
+	hasSeed bool
+}
+
+// NewReplay reads a recording from r. Recordings made before seeds were
+// kept don't have one.
+func NewReplay(r io.Reader) (*Replay, error) {
+	replay := &Replay{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) == 2 && fields[0] == "seed" {
+			seed, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			replay.seed = seed
+			replay.hasSeed = true
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected a tick and a direction", line)
+		}
+		tick, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		d := GetDirection(fields[1])
+		if d == None {
+			return nil, fmt.Errorf("line %d: unknown direction %q", line, fields[1])
+		}
+		if n := len(replay.intents); n > 0 && tick <= replay.intents[n-1].tick {
+			return nil, fmt.Errorf("line %d: tick %d is out of order", line, tick)
+		}
+		replay.intents = append(replay.intents, recordedIntent{tick, d})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+func (r *Replay) Intent(_ float64, _ WorldState) Direction {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	d := None
+	if r.next < len(r.intents) && r.intents[r.next].tick == r.tick {
+		d = r.intents[r.next].direction
+		r.next++
+	}
+	r.tick++
+	return d
+}
+
+// Seed returns the seed the recorded game's items were placed with. It
+// reports false if the recording doesn't have one.
+func (r *Replay) Seed() (int64, bool) {
+	return r.seed, r.hasSeed
+}
+
+// Done reports whether every recorded intent has been played back.
+func (r *Replay) Done() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.next >= len(r.intents)
+}
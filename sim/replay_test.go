@@ -0,0 +1,135 @@
+package sim
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kristinaspring/snake-go/events"
+)
+
+func TestRecordingRoundTrip(t *testing.T) {
+	directions := []Direction{Up, None, None, Left, None, Down}
+	tick := 0
+	r := Record(ControllerFunc(func(_ float64, _ WorldState) Direction {
+		d := directions[tick]
+		tick++
+		return d
+	}), 42)
+	for range directions {
+		r.Intent(0, WorldState{})
+	}
+
+	var b bytes.Buffer
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	if want := "seed 42\n0 up\n3 left\n5 down\n"; b.String() != want {
+		t.Errorf("wrote %q, want %q", b.String(), want)
+	}
+
+	replay, err := NewReplay(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seed, ok := replay.Seed(); !ok || seed != 42 {
+		t.Errorf("Seed() = %d, %v, want 42, true", seed, ok)
+	}
+	for i, want := range directions {
+		if got := replay.Intent(0, WorldState{}); got != want {
+			t.Errorf("tick %d: Intent() = %v, want %v", i, got, want)
+		}
+	}
+	if !replay.Done() {
+		t.Error("Done() = false after every intent was played back")
+	}
+}
+
+func TestReplayWithoutSeed(t *testing.T) {
+	// recordings from before seeds were kept still play back.
+	replay, err := NewReplay(strings.NewReader("1 right\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := replay.Seed(); ok {
+		t.Error("Seed() found a seed that wasn't recorded")
+	}
+	if d := replay.Intent(0, WorldState{}); d != None {
+		t.Errorf("tick 0: Intent() = %v, want none", d)
+	}
+	if d := replay.Intent(0, WorldState{}); d != Right {
+		t.Errorf("tick 1: Intent() = %v, want right", d)
+	}
+}
+
+func TestReplayBadRecording(t *testing.T) {
+	for _, recording := range []string{
+		"seed x\n",
+		"1 up\n1 down\n",
+		"1 sideways\n",
+		"1\n",
+	} {
+		if _, err := NewReplay(strings.NewReader(recording)); err == nil {
+			t.Errorf("NewReplay(%q) didn't fail", recording)
+		}
+	}
+}
+
+func TestReplayPlaysSameGame(t *testing.T) {
+	const seed = 7
+	edges := Edges{Right: 12, Top: 12}
+	config := SnakeConfig{Wrap: true, Movement: Grid, PixelsPerSec: 1, StartingFrames: 3, FramesToGrow: 1, Lives: 3}
+
+	// play a game steered towards wherever the item is, so it only plays
+	// out the same way if the items go to the same places.
+	play := func(seed int64, c Controller) WorldState {
+		world := NewGame(events.NewBus(), edges, config, BothDie, 1, seed)
+		world.SetController(0, c)
+		for i := 0; i < 200 && !world.Over(); i++ {
+			world.Steer(float64(i))
+			world.Step(float64(i), 1)
+		}
+		return world.State()
+	}
+	chase := ControllerFunc(func(_ float64, world WorldState) Direction {
+		head := Square(world.Snakes[0].Locations[0])
+		switch {
+		case head.X() < world.Item.X():
+			return Right
+		case head.X() > world.Item.X():
+			return Left
+		case head.Y() < world.Item.Y():
+			return Up
+		default:
+			return Down
+		}
+	})
+
+	recording := Record(chase, seed)
+	want := play(seed, recording)
+	if want.Snakes[0].Score == 0 {
+		t.Fatal("the recorded snake never ate anything")
+	}
+
+	var b bytes.Buffer
+	if _, err := recording.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := NewReplay(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, _ := replay.Seed()
+	if got := play(recorded, replay); !reflect.DeepEqual(got, want) {
+		t.Errorf("replay ended with %+v, want %+v", got, want)
+	}
+
+	// the same intents with the items somewhere else play another game.
+	b.Reset()
+	recording.WriteTo(&b)
+	replay, _ = NewReplay(&b)
+	if got := play(seed+1, replay); reflect.DeepEqual(got, want) {
+		t.Error("replay with another seed ended the same way")
+	}
+}
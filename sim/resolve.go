@@ -0,0 +1,207 @@
+package sim
+
+import (
+	"strings"
+
+	"github.com/kristinaspring/snake-go/events"
+)
+
+// Rule decides what happens when snakes run into each other head on, which
+// includes two snakes reaching the same item at the same time.
+type Rule int
+
+const (
+	// BothDie kills every snake in the crash.
+	BothDie Rule = iota
+	// LongerWins kills the shorter snake, which lets the longer one carry on
+	// and eat anything it has reached. Snakes as long as each other both die.
+	LongerWins
+	// Draw stops both snakes where they are for the step, so nobody dies and
+	// nobody gets the item.
+	Draw
+)
+
+func GetRule(r string) Rule {
+	lr := strings.ToLower(r)
+	switch lr {
+	case "longer-wins":
+		return LongerWins
+	case "draw":
+		return Draw
+	default:
+		return BothDie
+	}
+}
+
+// move is a step a snake wants to take, from where its head is to where it's
+// going next.
+type move struct {
+	snake    *Snake
+	from     Location
+	to       Location
+	offBoard bool
+
+	dead    bool
+	cause   events.Cause
+	blocked bool
+}
+
+func (m *move) kill(cause events.Cause) {
+	if !m.dead {
+		m.dead = true
+		m.cause = cause
+	}
+}
+
+// moveTogether moves every snake on by deltaT seconds at the same time, so
+// none of them gets an advantage from the order they're in. Snakes that take
+// more than one step do so in rounds, with every snake that still has a step
+// to take taking its next one together.
+func moveTogether(rule Rule, snakes []*Snake, deltaT float64) {
+	steps := make([]int, len(snakes))
+	rounds := 0
+	for i, s := range snakes {
+		steps[i] = s.prepare(deltaT)
+		if steps[i] > rounds {
+			rounds = steps[i]
+		}
+	}
+
+	for round := 0; round < rounds; round++ {
+		var moves []*move
+		index := make(map[*Snake]int)
+		for i, s := range snakes {
+			if steps[i] <= round {
+				continue
+			}
+			if m := s.propose(deltaT); m != nil {
+				moves = append(moves, m)
+				index[s] = i
+			}
+		}
+
+		resolve(rule, moves)
+
+		for _, m := range moves {
+			if m.dead {
+				// a snake that comes straight back waits for the next tick.
+				steps[index[m.snake]] = 0
+			}
+		}
+	}
+}
+
+// resolve settles every move against all the others before any of them are
+// made. Snakes that crash into a wall die whatever anyone else does. Snakes
+// that meet head on, either by moving into the same square or by swapping
+// squares, are dealt with by rule, and the snakes that lose are taken off the
+// board. Every other move is checked against where the snakes will be once
+// they have all moved, so a head can follow a tail that gets out of the way
+// in the same step.
+func resolve(rule Rule, moves []*move) {
+	for _, m := range moves {
+		if m.offBoard {
+			m.kill(events.CauseWall)
+		}
+	}
+
+	lost := make(map[*Snake]bool)
+	for i, a := range moves {
+		for _, b := range moves[i+1:] {
+			if !headOn(a, b) {
+				continue
+			}
+			switch rule {
+			case LongerWins:
+				la, lb := a.snake.locations.Len(), b.snake.locations.Len()
+				if la <= lb {
+					a.kill(events.CauseSnake)
+					lost[a.snake] = true
+				}
+				if lb <= la {
+					b.kill(events.CauseSnake)
+					lost[b.snake] = true
+				}
+			case Draw:
+				a.blocked = true
+				b.blocked = true
+			default:
+				a.kill(events.CauseSnake)
+				b.kill(events.CauseSnake)
+				lost[a.snake] = true
+				lost[b.snake] = true
+			}
+		}
+	}
+
+	// work out whose tails get out of the way before anyone eats, since a
+	// snake that eats this step grows and leaves its tail where it is.
+	vacating := make(map[*Snake]bool)
+	for _, m := range moves {
+		if !m.dead && !m.blocked {
+			vacating[m.snake] = m.snake.vacates(m.to)
+		}
+	}
+
+	for _, m := range moves {
+		if m.dead || m.blocked || m.snake.invulnerable > 0 {
+			continue
+		}
+		if m.snake.hitsSelf(m.to) {
+			m.kill(events.CauseSelf)
+			continue
+		}
+		for _, o := range m.snake.others {
+			other, ok := o.(*Snake)
+			if !ok {
+				if o.At(m.to) {
+					m.kill(events.CauseSnake)
+				}
+				continue
+			}
+			if !lost[other] && other.bodyAt(m.to, vacating[other]) {
+				m.kill(events.CauseSnake)
+			}
+		}
+	}
+
+	// the dead come off the board first, then everyone else moves their head
+	// and eats before tails follow. The item is moved on last, so it can't be
+	// put where a head has just gone, but can go where a tail has just left.
+	for _, m := range moves {
+		if m.dead {
+			m.snake.die(m.cause)
+		}
+	}
+	for _, m := range moves {
+		if !m.dead && !m.blocked {
+			m.snake.pushHead(m.to)
+		}
+	}
+	var ate []*Snake
+	for _, m := range moves {
+		if !m.dead && !m.blocked && m.snake.eat(m.to) {
+			ate = append(ate, m.snake)
+		}
+	}
+	for _, m := range moves {
+		if !m.dead && !m.blocked {
+			m.snake.followHead()
+		}
+	}
+	for _, s := range ate {
+		s.item.Reset(s.locations)
+	}
+}
+
+// headOn reports whether two moves run into each other head first, either
+// into the same square or through each other. Invulnerable snakes never do.
+func headOn(a *move, b *move) bool {
+	if a.offBoard || b.offBoard || a.snake.invulnerable > 0 || b.snake.invulnerable > 0 {
+		return false
+	}
+	if a.to.Equal(b.to) {
+		return true
+	}
+	return a.to.Equal(b.from) && b.to.Equal(a.from)
+}
@@ -0,0 +1,121 @@
+package sim
+
+import (
+	"container/list"
+	"testing"
+
+	"github.com/kristinaspring/snake-go/events"
+)
+
+// fixedItem is an item that stays in one square until it's eaten.
+type fixedItem struct {
+	at    Location
+	eaten bool
+}
+
+func (f *fixedItem) At(l Location) bool {
+	return !f.eaten && Square(l) == f.at
+}
+
+func (f *fixedItem) Reset(_ *list.List) {
+	f.eaten = true
+}
+
+// newGridSnakes creates grid snakes on the board occupancy keeps track of,
+// one for each body, going from head to tail and heading in the direction at
+// the same index. They move one square every second and grow by two squares
+// for each item.
+func newGridSnakes(bus *events.Bus, occupancy *Occupancy, item Tracker, bodies [][]Location, headings []Direction) []*Snake {
+	snakes := make([]*Snake, len(bodies))
+	for i, body := range bodies {
+		s := NewSnake(item, SnakeConfig{
+			Player:       i,
+			Events:       bus,
+			Edges:        occupancy.edges,
+			Occupancy:    occupancy,
+			Movement:     Grid,
+			PixelsPerSec: 1,
+			FramesToGrow: 2,
+			Lives:        1,
+		})
+		s.clear()
+		for j := len(body) - 1; j >= 0; j-- {
+			s.pushHead(body[j])
+		}
+		s.grow = 0
+		s.currDirection = headings[i]
+		snakes[i] = s
+	}
+	for _, s := range snakes {
+		others := make([]Tracker, 0, len(snakes)-1)
+		for _, o := range snakes {
+			if o != s {
+				others = append(others, o)
+			}
+		}
+		s.SetOtherSnakes(others...)
+	}
+	return snakes
+}
+
+func TestResolveTailThatMovesAway(t *testing.T) {
+	edges := Edges{Right: 10, Top: 10}
+	tests := []struct {
+		name string
+		// where the item is, in the way of the second snake or not.
+		item     Location
+		wantDied []int
+	}{
+		{
+			name:     "tail moves away",
+			item:     NewLocation(0, 0),
+			wantDied: nil,
+		},
+		{
+			name:     "tail stays while eating",
+			item:     NewLocation(7, 5),
+			wantDied: []int{0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bus := events.NewBus()
+			var died []int
+			events.Subscribe(bus, func(e events.SnakeDied) {
+				died = append(died, e.Player)
+			})
+
+			// the first snake's head goes into the second snake's tail, as
+			// the second snake moves on towards the item.
+			snakes := newGridSnakes(bus, NewOccupancy(edges), &fixedItem{at: test.item},
+				[][]Location{
+					{NewLocation(5, 4), NewLocation(5, 3)},
+					{NewLocation(6, 5), NewLocation(5, 5)},
+				},
+				[]Direction{Up, Right},
+			)
+			moveTogether(BothDie, snakes, 1)
+
+			if len(died) != len(test.wantDied) {
+				t.Fatalf("players that died = %v, want %v", died, test.wantDied)
+			}
+			for i := range died {
+				if died[i] != test.wantDied[i] {
+					t.Fatalf("players that died = %v, want %v", died, test.wantDied)
+				}
+			}
+			// only one of the snakes can end up in the tail's square.
+			in := 0
+			for player, s := range snakes {
+				in += s.config.Occupancy.Count(player, NewLocation(5, 5))
+			}
+			if in != 1 {
+				t.Errorf("pieces at (5, 5) = %d, want 1", in)
+			}
+			if len(test.wantDied) == 0 && !snakes[0].head().Equal(NewLocation(5, 5)) {
+				t.Errorf("head of the first snake = %v, want (5, 5)", snakes[0].head())
+			}
+		})
+	}
+}
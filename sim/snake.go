@@ -0,0 +1,768 @@
+// Package sim holds the rules of the game: how snakes move, grow, collide
+// and score, and where items go. It knows nothing about drawing, so it can
+// run without a window.
+package sim
+
+import (
+	"container/list"
+	"math"
+	"strings"
+
+	"github.com/kristinaspring/snake-go/events"
+)
+
+type Direction int
+
+const (
+	None Direction = iota
+	Up
+	Down
+	Right
+	Left
+)
+
+// GetDirection looks up a direction by its name, ignoring case. Anything it
+// doesn't know is None.
+func GetDirection(d string) Direction {
+	ld := strings.ToLower(d)
+	switch ld {
+	case "up":
+		return Up
+	case "down":
+		return Down
+	case "right":
+		return Right
+	case "left":
+		return Left
+	default:
+		return None
+	}
+}
+
+func (d Direction) String() string {
+	switch d {
+	case Up:
+		return "up"
+	case Down:
+		return "down"
+	case Right:
+		return "right"
+	case Left:
+		return "left"
+	default:
+		return "none"
+	}
+}
+
+// Opposite returns the direction going back the way d came.
+func (d Direction) Opposite() Direction {
+	switch d {
+	case Up:
+		return Down
+	case Down:
+		return Up
+	case Right:
+		return Left
+	case Left:
+		return Right
+	default:
+		return None
+	}
+}
+
+const (
+	DefaultSquareSize      = 10
+	DefaultPixelsPerSecond = 10
+	DefaultStartingFrames  = 12
+	DefaultFramesToGrow    = 4
+	DefaultThreshold       = 5.0
+	DefaultTurnBuffer      = 3
+	DefaultLives           = 3
+)
+
+type Point interface {
+	X() float64
+	Y() float64
+}
+
+// Location is a spot on the board, measured in squares.
+type Location struct {
+	x float64
+	y float64
+}
+
+func NewLocation(x float64, y float64) Location {
+	return Location{x: x, y: y}
+}
+
+func (l Location) X() float64 {
+	return l.x
+}
+
+func (l Location) Y() float64 {
+	return l.y
+}
+
+func (l Location) Equal(other Location) bool {
+	return int(l.x) == int(other.x) && int(l.y) == int(other.y)
+}
+
+// Next returns the location one square away from l in direction d.
+func (l Location) Next(d Direction) Location {
+	switch d {
+	case Up:
+		return Location{x: l.x, y: l.y + 1}
+	case Down:
+		return Location{x: l.x, y: l.y - 1}
+	case Left:
+		return Location{x: l.x - 1, y: l.y}
+	case Right:
+		return Location{x: l.x + 1, y: l.y}
+	}
+	return l
+}
+
+// Edges are the bounds of the board, measured in squares.
+type Edges struct {
+	Left   float64
+	Right  float64
+	Top    float64
+	Bottom float64
+}
+
+// Contains reports whether l is on a square inside the edges.
+func (e Edges) Contains(l Location) bool {
+	return int(l.y) >= int(e.Bottom) && int(l.y) < int(e.Top) && int(l.x) >= int(e.Left) && int(l.x) < int(e.Right)
+}
+
+// Wrap brings l back inside the edges as though they wrap around, so going
+// off one side comes back on at the other.
+func (e Edges) Wrap(l Location) Location {
+	return Location{
+		x: wrap(l.x, e.Left, e.Right-e.Left),
+		y: wrap(l.y, e.Bottom, e.Top-e.Bottom),
+	}
+}
+
+// Delta returns how far it is from one location to another the short way
+// round, on a board whose edges wrap around.
+func (e Edges) Delta(from Location, to Location) (float64, float64) {
+	return delta(to.x-from.x, e.Right-e.Left), delta(to.y-from.y, e.Top-e.Bottom)
+}
+
+func wrap(v float64, start float64, size float64) float64 {
+	return start + math.Mod(math.Mod(v-start, size)+size, size)
+}
+
+func delta(d float64, size float64) float64 {
+	if d > size/2 {
+		return d - size
+	}
+	if d < -size/2 {
+		return d + size
+	}
+	return d
+}
+
+type Snake struct {
+	config SnakeConfig
+
+	currDirection         Direction
+	currDirectionStartLoc Location
+	// turns waiting to be made, oldest first.
+	turns     []Direction
+	locations *list.List
+	grow      int
+	score     int
+	resets    int
+	// how far along a Grid snake is to its next square.
+	progress float64
+	lives    int
+	level    int
+	// seconds left before a dead snake comes back, and before a snake that
+	// has come back can be hit again.
+	respawnIn    float64
+	invulnerable float64
+
+	item   Tracker
+	others []Tracker
+}
+
+type SnakeConfig struct {
+	// Player identifies the snake in the events it publishes.
+	Player int
+	Events *events.Bus
+	Edges  Edges
+	// Occupancy is shared by every snake on the board and the item tracker,
+	// so they can all see what's where. A snake without one keeps its own.
+	Occupancy *Occupancy
+	// Wrap makes a snake going off one edge of the board come back on at
+	// the opposite edge, rather than dying.
+	Wrap             bool
+	StartingPosition Point
+	Movement         Movement
+	// SquareSize and Threshold decide how close to a square a Continuous
+	// snake has to be before it can turn.
+	SquareSize float64
+	// PixelsPerSec is how many squares the snake moves every second.
+	PixelsPerSec float64
+	// StartingFrames and FramesToGrow count ticks for a Continuous snake, and
+	// squares for a Grid snake.
+	StartingFrames int
+	FramesToGrow   int
+	Threshold      float64
+	// Difficulty speeds the snake up from PixelsPerSec, and makes it grow by
+	// more than FramesToGrow, as its score goes up.
+	Difficulty Difficulty
+	// TurnBuffer is how many turns can be waiting to be made at once. Any
+	// more than that are dropped.
+	TurnBuffer int
+	// Lives is how many times the snake can die before it's out of the game.
+	Lives int
+	// RespawnDelay is how many seconds a snake that still has lives left
+	// stays off the board after dying. After that it comes back as soon as
+	// nothing is in its starting square.
+	RespawnDelay float64
+	// Invulnerable is how many seconds a snake that has just come back can't
+	// run into, or be run into by, any snake.
+	Invulnerable float64
+}
+
+func NewSnake(itemTracker Tracker, config SnakeConfig) *Snake {
+	c := validateConfig(config)
+
+	l := list.New()
+
+	item := itemTracker
+	if item == nil {
+		item = defaultTracker{}
+	}
+
+	s := &Snake{
+		config:    c,
+		locations: l,
+		item:      item,
+	}
+	s.Reset(nil)
+	return s
+}
+
+func validateConfig(config SnakeConfig) SnakeConfig {
+	c := config
+	e := c.Edges
+	if c.Edges.Right < c.Edges.Left {
+		e.Right = c.Edges.Left
+		e.Left = c.Edges.Right
+	}
+	if c.Edges.Top < c.Edges.Bottom {
+		e.Top = c.Edges.Bottom
+		e.Bottom = c.Edges.Top
+	}
+	c.Edges = e
+
+	// snakes without a starting position on the board start in the middle.
+	if c.StartingPosition == nil || !e.Contains(Location{x: c.StartingPosition.X(), y: c.StartingPosition.Y()}) {
+		middleY := (e.Top-e.Bottom)/2.0 + e.Bottom
+		middleX := (e.Right-e.Left)/2.0 + e.Left
+		c.StartingPosition = Location{x: middleX, y: middleY}
+	}
+	if c.Movement == Grid {
+		// a Grid snake always sits right on a square.
+		c.StartingPosition = Location{x: math.Floor(c.StartingPosition.X()), y: math.Floor(c.StartingPosition.Y())}
+	}
+
+	if c.Occupancy == nil {
+		c.Occupancy = NewOccupancy(e)
+	}
+
+	if c.SquareSize <= 0 {
+		c.SquareSize = DefaultSquareSize
+	}
+
+	if c.PixelsPerSec <= 0 {
+		c.PixelsPerSec = DefaultPixelsPerSecond
+	}
+
+	if c.StartingFrames <= 0 {
+		c.StartingFrames = DefaultStartingFrames
+	}
+
+	if c.FramesToGrow < 0 {
+		c.FramesToGrow = DefaultFramesToGrow
+	}
+
+	if c.Difficulty.SpeedScale <= 0 {
+		c.Difficulty.SpeedScale = 1
+	}
+
+	if c.Threshold <= 0 {
+		c.Threshold = DefaultThreshold
+	}
+
+	if c.TurnBuffer <= 0 {
+		c.TurnBuffer = DefaultTurnBuffer
+	}
+
+	if c.Lives <= 0 {
+		c.Lives = DefaultLives
+	}
+
+	if c.RespawnDelay < 0 {
+		c.RespawnDelay = 0
+	}
+
+	if c.Invulnerable < 0 {
+		c.Invulnerable = 0
+	}
+
+	return c
+}
+
+// SetOtherSnakes sets the other snakes on the board, which the snake dies if
+// it runs into.
+func (s *Snake) SetOtherSnakes(others ...Tracker) {
+	s.others = s.others[:0]
+	for _, o := range others {
+		if o != nil {
+			s.others = append(s.others, o)
+		}
+	}
+}
+
+// SetDirection queues up a turn, to be made once the snake reaches a square
+// where it can turn. Turns are made in the order they're queued, one per
+// square. A turn that would go back the way the snake will be heading after
+// the turns already queued, or that doesn't change direction at all, is
+// ignored, as is any turn once the queue is full.
+func (s *Snake) SetDirection(d Direction) {
+	if d == None || len(s.turns) >= s.config.TurnBuffer || !s.OnBoard() {
+		return
+	}
+
+	last := s.currDirection
+	if len(s.turns) > 0 {
+		last = s.turns[len(s.turns)-1]
+	}
+	// don't let the snake do a 180 turn
+	if d == last || d == last.Opposite() {
+		return
+	}
+	s.turns = append(s.turns, d)
+}
+
+// nextTurn returns the turn the snake will make next, or None.
+func (s *Snake) nextTurn() Direction {
+	if len(s.turns) == 0 {
+		return None
+	}
+	return s.turns[0]
+}
+
+// takeTurn makes the next turn in the queue, if there is one.
+func (s *Snake) takeTurn() {
+	if len(s.turns) == 0 {
+		return
+	}
+	s.currDirection = s.turns[0]
+	s.turns = append(s.turns[:0], s.turns[1:]...)
+}
+
+// At reports whether any part of the snake is at l. A snake that is
+// invulnerable isn't at anywhere, so other snakes go right through it.
+func (s *Snake) At(l Location) bool {
+	if s.invulnerable > 0 {
+		return false
+	}
+	return s.config.Occupancy.Has(s.config.Player, l)
+}
+
+// Lives returns how many lives the snake has left. A snake with none left is
+// out of the game.
+func (s *Snake) Lives() int {
+	return s.lives
+}
+
+// Level returns the level the snake is at, going by its score.
+func (s *Snake) Level() int {
+	return s.level
+}
+
+// speed is how many squares a second the snake moves at its level.
+func (s *Snake) speed() float64 {
+	return s.config.Difficulty.Speed(s.config.PixelsPerSec, s.level)
+}
+
+// OnBoard reports whether the snake is on the board, rather than out of the
+// game or waiting to come back after dying.
+func (s *Snake) OnBoard() bool {
+	return s.locations.Len() > 0
+}
+
+// Player returns the player the snake belongs to.
+func (s *Snake) Player() int {
+	return s.config.Player
+}
+
+// Score returns the number of items the snake has eaten since it was last
+// reset. Dying doesn't lose any.
+func (s *Snake) Score() int {
+	return s.score
+}
+
+// SnakeState is a snapshot of a snake, taken at the end of a tick.
+type SnakeState struct {
+	// Locations goes from the head of the snake to its tail.
+	Locations []Location
+	Score     int
+	// Direction is the way the snake will be heading once it's able to turn.
+	Direction Direction
+	// Moving is the way the snake is going now, which is Direction unless
+	// it has a turn waiting to be made.
+	Moving Direction
+	// Speed is how many squares the snake moves every second.
+	Speed float64
+	// Progress is how far along a Grid snake is to its next square, from 0
+	// up to 1. It's always 0 for a Continuous snake.
+	Progress float64
+	Movement Movement
+	// Growing is how many more steps the snake's tail will stay where it is
+	// while the snake grows.
+	Growing int
+	// Lives is how many lives the snake has left.
+	Lives int
+	// Invulnerable is set while the snake can't be run into.
+	Invulnerable bool
+	// Resets counts how many times the snake has been reset, died or come
+	// back, so snapshots from either side of one can be told apart.
+	Resets int
+}
+
+// State takes a snapshot of the snake's current locations and score.
+func (s *Snake) State() SnakeState {
+	locations := make([]Location, 0, s.locations.Len())
+	for e := s.locations.Front(); e != nil; e = e.Next() {
+		l := e.Value.(Point)
+		locations = append(locations, Location{x: l.X(), y: l.Y()})
+	}
+	return SnakeState{
+		Locations:    locations,
+		Score:        s.score,
+		Direction:    s.heading(),
+		Moving:       s.currDirection,
+		Speed:        s.speed(),
+		Progress:     s.progress,
+		Movement:     s.config.Movement,
+		Growing:      s.grow,
+		Lives:        s.lives,
+		Invulnerable: s.invulnerable > 0,
+		Resets:       s.resets,
+	}
+}
+
+// heading is the direction the snake will go in next.
+func (s *Snake) heading() Direction {
+	if next := s.nextTurn(); next != None {
+		return next
+	}
+	return s.currDirection
+}
+
+// Tick moves the snake on by deltaT seconds, as though it were the only snake
+// moving. A World moves all of its snakes at the same time instead.
+func (s *Snake) Tick(t float64, deltaT float64) {
+	moveTogether(BothDie, []*Snake{s}, deltaT)
+}
+
+// prepare counts down the snake's timers, brings it back if it's due to come
+// back, and returns how many steps it has to take to move on by deltaT
+// seconds. A Continuous snake always takes one step, however small, and a
+// Grid snake takes one step for every square it has had time to move.
+func (s *Snake) prepare(deltaT float64) int {
+	if s.invulnerable > 0 {
+		s.invulnerable -= deltaT
+	}
+	if !s.OnBoard() {
+		if s.lives > 0 {
+			s.respawnIn -= deltaT
+			if s.respawnIn <= 0 && s.startFree() {
+				s.spawn()
+			}
+		}
+		return 0
+	}
+
+	if s.config.Movement != Grid {
+		return 1
+	}
+
+	// wait to be pointed somewhere before starting.
+	if s.currDirection == None && s.nextTurn() == None {
+		return 0
+	}
+	s.progress += s.speed() * deltaT
+	steps := int(s.progress)
+	s.progress -= float64(steps)
+	return steps
+}
+
+// propose works out where the snake's head goes on its next step, turning
+// first if it can. It returns nil if the snake isn't going anywhere.
+func (s *Snake) propose(deltaT float64) *move {
+	if s.config.Movement == Grid {
+		s.takeTurn()
+		if s.currDirection == None {
+			return nil
+		}
+		h := s.head()
+		to, ok := s.onBoard(h.Next(s.currDirection))
+		return &move{snake: s, from: h, to: to, offBoard: !ok}
+	}
+
+	h := s.head()
+	newX := h.X()
+	newY := h.Y()
+
+	pps := s.speed()
+
+	switch s.currDirection {
+	case Up:
+		newY = h.Y() + pps*deltaT
+	case Down:
+		newY = h.Y() - pps*deltaT
+	case Left:
+		newX = h.X() - pps*deltaT
+	case Right:
+		newX = h.X() + pps*deltaT
+	}
+
+	threshold := s.config.Threshold
+	if s.nextTurn() != None {
+		ss := s.config.SquareSize
+		xCheck := math.Mod(newX, ss)
+		yCheck := math.Mod(newY, ss)
+
+		xRound := math.Round(newX)
+		yRound := math.Round(newY)
+		// switch directions if we're ready
+		if (xCheck < threshold || (ss-xCheck) < threshold || yCheck < threshold || (ss-yCheck) < threshold) &&
+			(math.Abs(xRound-s.currDirectionStartLoc.X()) >= 1 || math.Abs(yRound-s.currDirectionStartLoc.Y()) >= 1) {
+			s.takeTurn()
+			newX = xRound
+			newY = yRound
+			s.currDirectionStartLoc = Location{x: newX, y: newY}
+		}
+	}
+
+	// check that the new spot won't be outside of the game board
+	newSquare, ok := s.onBoard(Location{x: newX, y: newY})
+	if !ok {
+		return &move{snake: s, from: h, to: newSquare, offBoard: true}
+	}
+
+	// if we're currently going nowhere, we're done here
+	if s.currDirection == None {
+		return nil
+	}
+	return &move{snake: s, from: h, to: newSquare}
+}
+
+// followHead brings the tail of the snake along after its head has moved,
+// unless it's growing.
+func (s *Snake) followHead() {
+	if s.grow > 0 {
+		s.grow--
+		return
+	}
+
+	// remove the last item from the list
+	s.dropTail()
+}
+
+// bodyAt reports whether any part of the snake is at l after its next step.
+// If vacating is set, its tail gets out of the way as its head moves on. A
+// snake that's invulnerable isn't at anywhere.
+func (s *Snake) bodyAt(l Location, vacating bool) bool {
+	if s.invulnerable > 0 {
+		return false
+	}
+	n := s.config.Occupancy.Count(s.config.Player, l)
+	if vacating && s.OnBoard() && s.locations.Back().Value.(Location).Equal(l) {
+		n--
+	}
+	return n > 0
+}
+
+// vacates reports whether the snake's tail gets out of the way as its head
+// moves to l, which it doesn't if it's growing or about to eat there.
+func (s *Snake) vacates(l Location) bool {
+	return s.grow == 0 && !s.item.At(l)
+}
+
+// hitsSelf reports whether the snake with its head at l runs into the rest of
+// itself. For a Continuous snake, only the pieces in the squares around l are
+// looked at.
+func (s *Snake) hitsSelf(l Location) bool {
+	if s.config.Movement == Grid {
+		return s.bodyAt(l, s.vacates(l))
+	}
+
+	// skip the first few, those will be too close
+	var neck [5]Location
+	n := 0
+	e := s.locations.Front()
+	for ; e != nil && n < len(neck); e = e.Next() {
+		neck[n] = e.Value.(Location)
+		n++
+	}
+	if e == nil {
+		return false
+	}
+
+	const near = 0.3
+	for _, corner := range []Location{
+		{x: l.x - near, y: l.y - near},
+		{x: l.x + near, y: l.y - near},
+		{x: l.x - near, y: l.y + near},
+		{x: l.x + near, y: l.y + near},
+	} {
+		square, ok := s.onBoard(corner)
+		if !ok {
+			continue
+		}
+	pieces:
+		for _, p := range s.config.Occupancy.pieces(square) {
+			if p.player != s.config.Player {
+				continue
+			}
+			for _, skip := range neck[:n] {
+				if p.location == skip {
+					continue pieces
+				}
+			}
+			dx, dy := s.offset(p.location, l)
+			if math.Abs(dx) < near && math.Abs(dy) < near {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// head returns where the head of the snake is.
+func (s *Snake) head() Location {
+	return s.locations.Front().Value.(Location)
+}
+
+// pushHead moves the head of the snake to l.
+func (s *Snake) pushHead(l Location) {
+	s.locations.PushFront(l)
+	s.config.Occupancy.Add(s.config.Player, l)
+}
+
+// dropTail takes the end of the snake's tail away.
+func (s *Snake) dropTail() {
+	tail := s.locations.Remove(s.locations.Back()).(Location)
+	s.config.Occupancy.Remove(s.config.Player, tail)
+}
+
+// clear takes the whole snake off the board.
+func (s *Snake) clear() {
+	for e := s.locations.Front(); e != nil; e = e.Next() {
+		s.config.Occupancy.Remove(s.config.Player, e.Value.(Location))
+	}
+	s.locations.Init()
+}
+
+// eat eats the item if it's at l, which makes the snake grow, scores a point
+// and maybe takes the snake up a level. It reports whether it did. The item
+// has to be moved on afterwards.
+func (s *Snake) eat(l Location) bool {
+	if !s.item.At(l) {
+		return false
+	}
+	s.config.Events.Publish(events.ItemEaten{Player: s.config.Player, X: int(l.X()), Y: int(l.Y())})
+	s.grow += s.config.Difficulty.Growth(s.config.FramesToGrow, s.level)
+	s.score++
+	s.config.Events.Publish(events.ScoreChanged{Player: s.config.Player, Score: s.score})
+
+	if level := s.config.Difficulty.Level(s.score); level != s.level {
+		s.level = level
+		s.config.Events.Publish(events.LevelChanged{Player: s.config.Player, Level: s.level})
+	}
+	return true
+}
+
+// onBoard returns where l is on the board, wrapping it around if the board
+// wraps. It reports false if l is off a board that doesn't.
+func (s *Snake) onBoard(l Location) (Location, bool) {
+	if s.config.Wrap {
+		return s.config.Edges.Wrap(l), true
+	}
+	return l, s.config.Edges.Contains(l)
+}
+
+// offset returns how far it is from p to l, the short way round if the board
+// wraps.
+func (s *Snake) offset(p Point, l Location) (float64, float64) {
+	from := Location{x: p.X(), y: p.Y()}
+	if s.config.Wrap {
+		return s.config.Edges.Delta(from, l)
+	}
+	return l.x - from.x, l.y - from.y
+}
+
+// Disqualify takes the snake out of the game for good, as though it had died
+// with no lives left.
+func (s *Snake) Disqualify() {
+	if s.lives <= 0 {
+		return
+	}
+	s.lives = 1
+	s.die(events.CauseDisqualified)
+}
+
+// die tells everyone why the snake died before resetting it.
+func (s *Snake) die(cause events.Cause) {
+	s.lives--
+	s.config.Events.Publish(events.SnakeDied{Player: s.config.Player, Cause: cause, Score: s.score, Lives: s.lives})
+
+	// take the snake off the board until it comes back.
+	s.clear()
+	s.turns = s.turns[:0]
+	s.invulnerable = 0
+	s.respawnIn = s.config.RespawnDelay
+	s.resets++
+	if s.lives > 0 && s.respawnIn <= 0 && s.startFree() {
+		s.spawn()
+	}
+}
+
+// spawn puts the snake back at its starting position, at its starting size.
+func (s *Snake) spawn() {
+	s.currDirection = None
+	s.turns = s.turns[:0]
+	s.clear()
+	s.pushHead(Location{x: s.config.StartingPosition.X(), y: s.config.StartingPosition.Y()})
+	s.currDirectionStartLoc = Location{x: s.config.StartingPosition.X() - 2.0, y: s.config.StartingPosition.Y() - 2.0}
+	s.grow = s.config.StartingFrames
+	s.progress = 0
+	s.respawnIn = 0
+	s.invulnerable = s.config.Invulnerable
+	s.resets++
+}
+
+// startFree reports whether nothing is in the square the snake starts in, so
+// it can come back without landing on anyone.
+func (s *Snake) startFree() bool {
+	return !s.config.Occupancy.Occupied(Location{x: s.config.StartingPosition.X(), y: s.config.StartingPosition.Y()})
+}
+
+// game is lost, bring everything back to the beginning
+func (s *Snake) Reset(_ *list.List) {
+	s.spawn()
+	// there's nothing to be invulnerable to at the start of a game.
+	s.invulnerable = 0
+	s.score = 0
+	s.level = s.config.Difficulty.Level(0)
+	s.lives = s.config.Lives
+}
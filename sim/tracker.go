@@ -0,0 +1,159 @@
+package sim
+
+import (
+	"container/list"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/kristinaspring/snake-go/events"
+)
+
+type Tracker interface {
+	At(Location) bool
+	Reset(*list.List)
+}
+
+type defaultTracker struct{}
+
+func (d defaultTracker) At(_ Location) bool {
+	return false
+}
+
+func (d defaultTracker) Reset(_ *list.List) {}
+
+// SingleTracker keeps track of a single item on the board.
+type SingleTracker struct {
+	randomGen *rand.Rand
+
+	currLocation Location
+	// set when there was nowhere left to put the item.
+	full   bool
+	lock   sync.RWMutex
+	events *events.Bus
+
+	edges     Edges
+	occupancy *Occupancy
+}
+
+// NewSingleTracker creates a SingleTracker for an item somewhere within
+// edges. If occupancy isn't nil, the item is only ever put in squares it
+// says are free.
+func NewSingleTracker(edges Edges, bus *events.Bus, occupancy *Occupancy) *SingleTracker {
+	e := edges
+	if edges.Right < edges.Left {
+		e.Right = edges.Left
+		e.Left = edges.Right
+	}
+	if edges.Top < edges.Bottom {
+		e.Top = edges.Bottom
+		e.Bottom = edges.Top
+	}
+
+	s := SingleTracker{
+		randomGen: rand.New(rand.NewSource(time.Now().UnixNano())),
+		edges:     e,
+		events:    bus,
+		occupancy: occupancy,
+	}
+	s.Reset(nil)
+	return &s
+}
+
+func (s *SingleTracker) At(l Location) bool {
+	s.lock.RLock()
+	if s.full || float64(int(l.X())) != s.currLocation.X() || float64(int(l.Y())) != s.currLocation.Y() {
+		s.lock.RUnlock()
+		return false
+	}
+	s.lock.RUnlock()
+	return true
+}
+
+// Seed makes the item go to the same places every time, for a given seed,
+// from the next time it's moved on. Items go somewhere different every game
+// unless it's called.
+func (s *SingleTracker) Seed(seed int64) {
+	s.randomGen = rand.New(rand.NewSource(seed))
+}
+
+func (s *SingleTracker) Reset(l *list.List) {
+	loc, ok := s.findNewLocation(l)
+	s.lock.Lock()
+	s.full = !ok
+	if ok {
+		s.currLocation = loc
+	}
+	s.lock.Unlock()
+	if !ok {
+		s.events.Publish(events.BoardFull{})
+		return
+	}
+	s.events.Publish(events.ItemPlaced{X: int(loc.x), Y: int(loc.y)})
+}
+
+// Full reports whether there was nowhere left to put the item the last time
+// it was moved. The item can't be eaten while the board is full.
+func (s *SingleTracker) Full() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.full
+}
+
+// Location returns where the item currently is.
+func (s *SingleTracker) Location() Location {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.currLocation
+}
+
+// findNewLocation picks a free square for the item, avoiding locations. It
+// reports false if there are no free squares left.
+func (s *SingleTracker) findNewLocation(locations *list.List) (Location, bool) {
+	if s.occupancy != nil {
+		return s.occupancy.RandomFree(s.randomGen)
+	}
+
+	// every square, including the ones along the edges, can have an item,
+	// since on a board that wraps they're no different from any other.
+	gridX := int(s.edges.Right - s.edges.Left)
+	gridY := int(s.edges.Top - s.edges.Bottom)
+	if locations == nil || locations.Len() < 1 {
+		return Location{
+			x: float64(s.randomGen.Intn(gridX)) + s.edges.Left,
+			y: float64(s.randomGen.Intn(gridY)) + s.edges.Bottom,
+		}, true
+	}
+	for i := 0; i < gridX*gridY; i++ {
+		newLocation := Location{
+			x: float64(s.randomGen.Intn(gridX)) + s.edges.Left,
+			y: float64(s.randomGen.Intn(gridY)) + s.edges.Bottom,
+		}
+		if !pointInList(newLocation, locations) {
+			return newLocation, true
+		}
+	}
+
+	// the board is getting full, so rather than keep guessing, go through
+	// every square to find one that's free, if there are any.
+	for x := 0; x < gridX; x++ {
+		for y := 0; y < gridY; y++ {
+			newLocation := Location{x: float64(x) + s.edges.Left, y: float64(y) + s.edges.Bottom}
+			if !pointInList(newLocation, locations) {
+				return newLocation, true
+			}
+		}
+	}
+	return Location{}, false
+}
+
+func pointInList(point Location, locations *list.List) bool {
+	root := locations.Front()
+	for root != nil {
+		if root.Value.(Location).Equal(point) {
+			return true
+		}
+		root = root.Next()
+	}
+	return false
+}
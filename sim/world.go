@@ -0,0 +1,270 @@
+package sim
+
+import (
+	"container/list"
+
+	"github.com/kristinaspring/snake-go/events"
+)
+
+// World is the board, the item on it and the snakes going after it.
+type World struct {
+	events *events.Bus
+	item   *SingleTracker
+	snakes []*Snake
+	rule   Rule
+	edges  Edges
+	wrap   bool
+	// what drives each snake, if anything does.
+	controllers []Controller
+
+	// the score each player that died this step died with.
+	died map[int]int
+	// set when the item had nowhere left to go this step.
+	full      bool
+	lastEater int
+	over      bool
+	won       bool
+	winner    int
+}
+
+// WorldState is a snapshot of the world, taken at the end of a step.
+type WorldState struct {
+	Snakes []SnakeState
+	Item   Location
+	// Edges are the edges of the board, and Wrap is set if snakes going off
+	// one come back on at the opposite one.
+	Edges Edges
+	Wrap  bool
+	// Over is set once every snake has run out of lives, or once a player
+	// has won.
+	Over bool
+	// Won is set if the game is over because Winner filled the board.
+	Won    bool
+	Winner int
+}
+
+// NewWorld creates a World out of an item and the snakes after it. The
+// snakes should publish their events to bus. Every snake is set to run into
+// all of the others.
+func NewWorld(bus *events.Bus, item *SingleTracker, snakes ...*Snake) *World {
+	for _, s := range snakes {
+		others := make([]Tracker, 0, len(snakes)-1)
+		for _, o := range snakes {
+			if o != s {
+				others = append(others, o)
+			}
+		}
+		s.SetOtherSnakes(others...)
+	}
+
+	w := &World{
+		events: bus,
+		item:   item,
+		snakes: snakes,
+		edges:  item.edges,
+		died:   make(map[int]int),
+	}
+	for _, s := range snakes {
+		w.wrap = w.wrap || s.config.Wrap
+	}
+	events.Subscribe(bus, w.snakeDied)
+	events.Subscribe(bus, w.itemEaten)
+	events.Subscribe(bus, w.boardFull)
+	return w
+}
+
+// NewGame sets up a world on a board with edges for n snakes, all set up by
+// config and publishing their events to bus, that meet head on by rule.
+// Every snake is given its player, the edges and a shared occupancy. A single
+// snake starts in the middle of the board, and more are spread out along the
+// diagonal. Items are placed using seed, starting with the first one.
+func NewGame(bus *events.Bus, edges Edges, config SnakeConfig, rule Rule, n int, seed int64) *World {
+	occupancy := NewOccupancy(edges)
+	item := NewSingleTracker(edges, bus, occupancy)
+	item.Seed(seed)
+
+	c := config
+	c.Events = bus
+	c.Edges = edges
+	c.Occupancy = occupancy
+	snakes := make([]*Snake, n)
+	for i := range snakes {
+		c.Player = i
+		c.StartingPosition = nil
+		if n > 1 {
+			c.StartingPosition = NewLocation(
+				float64(int(float64(i+1)*(edges.Right-edges.Left)/float64(n+1)))+edges.Left,
+				float64(int(float64(i+1)*(edges.Top-edges.Bottom)/float64(n+1)))+edges.Bottom,
+			)
+		}
+		snakes[i] = NewSnake(item, c)
+	}
+
+	w := NewWorld(bus, item, snakes...)
+	w.SetRule(rule)
+	w.RelocateItem()
+	return w
+}
+
+// Snakes returns the snakes in the world, in the order they move.
+func (w *World) Snakes() []*Snake {
+	return w.snakes
+}
+
+// Item returns the item the snakes are after.
+func (w *World) Item() *SingleTracker {
+	return w.item
+}
+
+// SetController has c drive the snake at index in Snakes. Snakes without a
+// controller only turn when SetDirection is called on them.
+func (w *World) SetController(index int, c Controller) {
+	if index < 0 || index >= len(w.snakes) {
+		return
+	}
+	if w.controllers == nil {
+		w.controllers = make([]Controller, len(w.snakes))
+	}
+	w.controllers[index] = c
+}
+
+// Steer asks every snake's controller which way it should go, and turns it
+// that way. Snakes whose controllers have forfeited are disqualified. It does
+// nothing once the game is over.
+func (w *World) Steer(t float64) {
+	if w.over || w.controllers == nil {
+		return
+	}
+	state := w.State()
+	for i, c := range w.controllers {
+		if c == nil {
+			continue
+		}
+		w.snakes[i].SetDirection(c.Intent(t, state))
+		if f, ok := c.(Forfeiter); ok && f.Forfeited() != nil {
+			w.snakes[i].Disqualify()
+		}
+	}
+}
+
+// SetRule sets what happens when snakes meet head on. It's BothDie unless
+// set otherwise.
+func (w *World) SetRule(r Rule) {
+	w.rule = r
+}
+
+// Over reports whether every snake has run out of lives.
+func (w *World) Over() bool {
+	return w.over
+}
+
+// Step moves every snake forward by deltaT seconds, all at the same time. If
+// the board filled up, the player who ate last wins and a Victory event is
+// published once they have all moved. Otherwise if any of them died, a RoundOver event is
+// published, followed by a GameOver event if none of them have any lives
+// left. Once the game is over, Step does nothing until Restart is called.
+func (w *World) Step(t float64, deltaT float64) {
+	if w.over {
+		return
+	}
+	moveTogether(w.rule, w.snakes, deltaT)
+
+	if w.full {
+		w.full = false
+		w.over = true
+		w.won = true
+		w.winner = w.lastEater
+		for player := range w.died {
+			delete(w.died, player)
+		}
+		w.events.Publish(events.Victory{Player: w.winner, Scores: w.scores()})
+		return
+	}
+
+	if len(w.died) == 0 {
+		return
+	}
+	scores := make([]int, len(w.snakes))
+	for i, s := range w.snakes {
+		scores[i] = s.Score()
+		if score, ok := w.died[s.Player()]; ok {
+			scores[i] = score
+		}
+	}
+	for player := range w.died {
+		delete(w.died, player)
+	}
+	w.events.Publish(events.RoundOver{Scores: scores})
+
+	for _, s := range w.snakes {
+		if s.Lives() > 0 {
+			return
+		}
+	}
+	w.over = true
+	w.events.Publish(events.GameOver{Scores: w.scores()})
+}
+
+// scores returns every player's score.
+func (w *World) scores() []int {
+	scores := make([]int, len(w.snakes))
+	for i, s := range w.snakes {
+		scores[i] = s.Score()
+	}
+	return scores
+}
+
+// Restart starts a new game with every snake back at the start with all of
+// its lives.
+func (w *World) Restart() {
+	for _, s := range w.snakes {
+		score := s.Score()
+		s.Reset(nil)
+		if score != 0 {
+			w.events.Publish(events.ScoreChanged{Player: s.Player(), Score: 0})
+		}
+	}
+	w.RelocateItem()
+	w.full = false
+	w.over = false
+	w.won = false
+	w.events.Publish(events.GameStarted{})
+}
+
+// RelocateItem moves the item to a spot no snake is in.
+func (w *World) RelocateItem() {
+	occupied := list.New()
+	for _, s := range w.snakes {
+		occupied.PushBackList(s.locations)
+	}
+	w.item.Reset(occupied)
+}
+
+// State takes a snapshot of the world.
+func (w *World) State() WorldState {
+	state := WorldState{
+		Snakes: make([]SnakeState, len(w.snakes)),
+		Item:   w.item.Location(),
+		Edges:  w.edges,
+		Wrap:   w.wrap,
+		Over:   w.over,
+		Won:    w.won,
+		Winner: w.winner,
+	}
+	for i, s := range w.snakes {
+		state.Snakes[i] = s.State()
+	}
+	return state
+}
+
+func (w *World) snakeDied(e events.SnakeDied) {
+	w.died[e.Player] = e.Score
+}
+
+func (w *World) itemEaten(e events.ItemEaten) {
+	w.lastEater = e.Player
+}
+
+func (w *World) boardFull(_ events.BoardFull) {
+	w.full = true
+}
@@ -0,0 +1,576 @@
+package sim
+
+import (
+	"container/list"
+	"testing"
+
+	"github.com/kristinaspring/snake-go/events"
+)
+
+func TestWorldFillsBoard(t *testing.T) {
+	// a board one square wide, which a snake that keeps growing fills by
+	// going straight up it.
+	const n = 6
+	edges := Edges{Right: 1, Top: n}
+	bus := events.NewBus()
+	occupancy := NewOccupancy(edges)
+	item := NewSingleTracker(edges, bus, occupancy)
+	item.Seed(1)
+	snake := NewSnake(item, SnakeConfig{
+		Events:           bus,
+		Edges:            edges,
+		Occupancy:        occupancy,
+		Movement:         Grid,
+		PixelsPerSec:     1,
+		StartingPosition: NewLocation(0, 0),
+		StartingFrames:   n,
+		FramesToGrow:     1,
+		Lives:            1,
+	})
+	world := NewWorld(bus, item, snake)
+	world.RelocateItem()
+	snake.SetDirection(Up)
+
+	var published []events.Event
+	bus.SubscribeAll(func(e events.Event) {
+		switch e.(type) {
+		case events.BoardFull, events.Victory:
+			published = append(published, e)
+		}
+	})
+
+	for i := 0; i < 2*n && !world.Over(); i++ {
+		world.Step(float64(i), 1)
+	}
+	if !world.Over() {
+		t.Fatalf("game isn't over after %d steps", 2*n)
+	}
+	if len(published) != 2 {
+		t.Fatalf("published %v, want BoardFull then Victory", published)
+	}
+	if _, ok := published[0].(events.BoardFull); !ok {
+		t.Errorf("first event = %T, want events.BoardFull", published[0])
+	}
+	if v, ok := published[1].(events.Victory); !ok || v.Player != 0 {
+		t.Errorf("second event = %#v, want a Victory for player 0", published[1])
+	}
+
+	if !item.Full() {
+		t.Error("item isn't full")
+	}
+	for y := 0; y < n; y++ {
+		if l := NewLocation(0, float64(y)); item.At(l) {
+			t.Errorf("item is at %v on a full board", l)
+		}
+	}
+
+	before := world.State()
+	var after []events.Event
+	bus.SubscribeAll(func(e events.Event) {
+		after = append(after, e)
+	})
+	world.Step(float64(2*n), 1)
+	if len(after) != 0 {
+		t.Errorf("Step after the win published %v", after)
+	}
+	state := world.State()
+	if !state.Won || state.Winner != 0 {
+		t.Errorf("Won = %v, Winner = %d, want player 0 to have won", state.Won, state.Winner)
+	}
+	got, want := state.Snakes[0], before.Snakes[0]
+	if got.Score != want.Score || len(got.Locations) != len(want.Locations) ||
+		!got.Locations[0].Equal(want.Locations[0]) {
+		t.Errorf("snake after the win = %+v, want %+v", got, want)
+	}
+}
+
+func TestItemGoesWhereTailLeft(t *testing.T) {
+	// a row of four squares. The first snake eats the item in the last free
+	// square as a continuous snake's tail leaves the square next to it.
+	edges := Edges{Right: 4, Top: 1}
+	bus := events.NewBus()
+	occupancy := NewOccupancy(edges)
+	item := NewSingleTracker(edges, bus, occupancy)
+	item.Seed(1)
+
+	eater := newGridSnakes(bus, occupancy, item, [][]Location{{NewLocation(0, 0)}}, []Direction{Right})[0]
+	mover := NewSnake(item, SnakeConfig{
+		Player:       1,
+		Events:       bus,
+		Edges:        edges,
+		Occupancy:    occupancy,
+		Movement:     Continuous,
+		SquareSize:   10,
+		PixelsPerSec: 0.1,
+		Lives:        1,
+	})
+	mover.clear()
+	for _, x := range []float64{2.9, 3.0, 3.2} {
+		mover.pushHead(NewLocation(x, 0))
+	}
+	mover.grow = 0
+	mover.currDirection = Right
+	eater.SetOtherSnakes(mover)
+	mover.SetOtherSnakes(eater)
+	item.currLocation = NewLocation(1, 0)
+
+	var full bool
+	events.Subscribe(bus, func(_ events.BoardFull) {
+		full = true
+	})
+	// the eater moves a whole square and the other snake a tenth of one.
+	moveTogether(BothDie, []*Snake{eater, mover}, 1)
+
+	if eater.Score() != 1 {
+		t.Fatalf("score = %d, want 1", eater.Score())
+	}
+	if full {
+		t.Error("board filled up as a tail left a square")
+	}
+	if want := NewLocation(2, 0); !item.Location().Equal(want) {
+		t.Errorf("item at %v, want %v", item.Location(), want)
+	}
+}
+
+func TestWrapCrossesEveryEdge(t *testing.T) {
+	edges := Edges{Right: 5, Top: 5}
+	tests := []struct {
+		name      string
+		movement  Movement
+		start     Location
+		direction Direction
+		deltaT    float64
+		want      Location
+	}{
+		{name: "grid right", movement: Grid, start: NewLocation(4, 2), direction: Right, deltaT: 1, want: NewLocation(0, 2)},
+		{name: "grid left", movement: Grid, start: NewLocation(0, 2), direction: Left, deltaT: 1, want: NewLocation(4, 2)},
+		{name: "grid up", movement: Grid, start: NewLocation(2, 4), direction: Up, deltaT: 1, want: NewLocation(2, 0)},
+		{name: "grid down", movement: Grid, start: NewLocation(2, 0), direction: Down, deltaT: 1, want: NewLocation(2, 4)},
+		{name: "continuous right", movement: Continuous, start: NewLocation(4.9, 2.5), direction: Right, deltaT: 0.2, want: NewLocation(0, 2)},
+		{name: "continuous left", movement: Continuous, start: NewLocation(0.1, 2.5), direction: Left, deltaT: 0.2, want: NewLocation(4, 2)},
+		{name: "continuous up", movement: Continuous, start: NewLocation(2.5, 4.9), direction: Up, deltaT: 0.2, want: NewLocation(2, 0)},
+		{name: "continuous down", movement: Continuous, start: NewLocation(2.5, 0.1), direction: Down, deltaT: 0.2, want: NewLocation(2, 4)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bus := events.NewBus()
+			var died []events.SnakeDied
+			events.Subscribe(bus, func(e events.SnakeDied) {
+				died = append(died, e)
+			})
+			occupancy := NewOccupancy(edges)
+			s := NewSnake(nil, SnakeConfig{
+				Events:           bus,
+				Edges:            edges,
+				Occupancy:        occupancy,
+				Wrap:             true,
+				Movement:         test.movement,
+				PixelsPerSec:     1,
+				StartingPosition: test.start,
+				StartingFrames:   1,
+				Lives:            1,
+			})
+			s.currDirection = test.direction
+			moveTogether(BothDie, []*Snake{s}, test.deltaT)
+
+			if len(died) > 0 {
+				t.Fatalf("snake died of %v going over the edge", died[0].Cause)
+			}
+			if head := Square(s.head()); !head.Equal(test.want) {
+				t.Errorf("head in square %v, want %v", head, test.want)
+			}
+			if !occupancy.Has(0, test.want) {
+				t.Errorf("occupancy doesn't have the head in %v", test.want)
+			}
+		})
+	}
+}
+
+func TestWrapCollidesAcrossEdge(t *testing.T) {
+	edges := Edges{Right: 5, Top: 5}
+	tests := []struct {
+		name     string
+		bodies   [][]Location
+		headings []Direction
+		wantDied []int
+	}{
+		{
+			name:     "swapping squares",
+			bodies:   [][]Location{{NewLocation(4, 2)}, {NewLocation(0, 2)}},
+			headings: []Direction{Right, Left},
+			wantDied: []int{0, 1},
+		},
+		{
+			name:     "into the same square",
+			bodies:   [][]Location{{NewLocation(4, 2)}, {NewLocation(1, 2)}},
+			headings: []Direction{Right, Left},
+			wantDied: []int{0, 1},
+		},
+		{
+			name:     "into a body",
+			bodies:   [][]Location{{NewLocation(4, 2)}, {NewLocation(0, 2), NewLocation(0, 3)}},
+			headings: []Direction{Right, None},
+			wantDied: []int{0},
+		},
+		{
+			name:     "passing by",
+			bodies:   [][]Location{{NewLocation(4, 2)}, {NewLocation(0, 3)}},
+			headings: []Direction{Right, Left},
+			wantDied: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bus := events.NewBus()
+			var died []int
+			events.Subscribe(bus, func(e events.SnakeDied) {
+				died = append(died, e.Player)
+			})
+			snakes := newGridSnakes(bus, NewOccupancy(edges), nil, test.bodies, test.headings)
+			for _, s := range snakes {
+				s.config.Wrap = true
+			}
+			moveTogether(BothDie, snakes, 1)
+
+			if len(died) != len(test.wantDied) {
+				t.Fatalf("players that died = %v, want %v", died, test.wantDied)
+			}
+			for i := range died {
+				if died[i] != test.wantDied[i] {
+					t.Fatalf("players that died = %v, want %v", died, test.wantDied)
+				}
+			}
+		})
+	}
+}
+
+func TestLivesAndGameOver(t *testing.T) {
+	edges := Edges{Right: 5, Top: 5}
+	bus := events.NewBus()
+	var published []string
+	var died []events.SnakeDied
+	bus.SubscribeAll(func(e events.Event) {
+		switch e := e.(type) {
+		case events.SnakeDied:
+			died = append(died, e)
+			published = append(published, "died")
+		case events.RoundOver:
+			published = append(published, "round over")
+		case events.GameOver:
+			published = append(published, "game over")
+		}
+	})
+	occupancy := NewOccupancy(edges)
+	item := NewSingleTracker(edges, bus, occupancy)
+	snake := NewSnake(item, SnakeConfig{
+		Events:           bus,
+		Edges:            edges,
+		Occupancy:        occupancy,
+		Movement:         Grid,
+		PixelsPerSec:     1,
+		StartingPosition: NewLocation(2, 2),
+		StartingFrames:   1,
+		Lives:            2,
+		RespawnDelay:     2,
+		Invulnerable:     1,
+	})
+	world := NewWorld(bus, item, snake)
+	world.RelocateItem()
+
+	// three steps takes the snake off the right of the board.
+	step := func(n int) {
+		for i := 0; i < n; i++ {
+			world.Step(0, 1)
+		}
+	}
+	snake.SetDirection(Right)
+	step(3)
+	if len(died) != 1 || died[0].Lives != 1 || died[0].Cause != events.CauseWall {
+		t.Fatalf("died %+v, want once into a wall with a life left", died)
+	}
+	if snake.OnBoard() || world.Over() {
+		t.Fatalf("OnBoard() = %v, Over() = %v straight after dying, want false and false", snake.OnBoard(), world.Over())
+	}
+
+	// it stays off the board until the delay is over, then comes back where
+	// it started, for a while invulnerable.
+	step(1)
+	if snake.OnBoard() {
+		t.Fatal("snake came back before the respawn delay was over")
+	}
+	step(1)
+	if !snake.OnBoard() {
+		t.Fatal("snake didn't come back after the respawn delay")
+	}
+	state := world.State().Snakes[0]
+	if !state.Invulnerable || !Square(state.Locations[0]).Equal(NewLocation(2, 2)) {
+		t.Errorf("came back at %v, invulnerable %v, want at (2, 2) and invulnerable", state.Locations[0], state.Invulnerable)
+	}
+	step(1)
+	if world.State().Snakes[0].Invulnerable {
+		t.Error("snake is still invulnerable after a second")
+	}
+
+	snake.SetDirection(Left)
+	step(3)
+	if len(died) != 2 || died[1].Lives != 0 {
+		t.Fatalf("died %+v, want a second time with no lives left", died)
+	}
+	if !world.Over() {
+		t.Fatal("game isn't over once the only snake is out of lives")
+	}
+	want := []string{"died", "round over", "died", "round over", "game over"}
+	if len(published) != len(want) {
+		t.Fatalf("published %v, want %v", published, want)
+	}
+	for i := range want {
+		if published[i] != want[i] {
+			t.Fatalf("published %v, want %v", published, want)
+		}
+	}
+
+	step(10)
+	if snake.OnBoard() {
+		t.Error("snake came back with no lives left")
+	}
+}
+
+func TestDisqualify(t *testing.T) {
+	bus := events.NewBus()
+	var died []events.SnakeDied
+	events.Subscribe(bus, func(e events.SnakeDied) {
+		died = append(died, e)
+	})
+	world := NewGame(bus, Edges{Right: 10, Top: 10}, SnakeConfig{Movement: Grid, Lives: 3}, BothDie, 2, 1)
+	snakes := world.Snakes()
+
+	snakes[0].Disqualify()
+	// disqualifying a snake that's already out does nothing.
+	snakes[0].Disqualify()
+	if len(died) != 1 || died[0].Cause != events.CauseDisqualified || died[0].Lives != 0 {
+		t.Fatalf("died %+v, want disqualified once with no lives left", died)
+	}
+
+	for i := 0; i < 10; i++ {
+		world.Step(float64(i), 1)
+	}
+	if snakes[0].OnBoard() || snakes[0].Lives() != 0 {
+		t.Errorf("disqualified snake has %d lives and OnBoard() = %v", snakes[0].Lives(), snakes[0].OnBoard())
+	}
+	if world.Over() {
+		t.Error("game is over while the other snake still has lives")
+	}
+}
+
+func TestRespawnWaitsForFreeStart(t *testing.T) {
+	edges := Edges{Right: 10, Top: 10}
+	bus := events.NewBus()
+	occupancy := NewOccupancy(edges)
+	// the first snake goes off the board straight away, while the second is
+	// on its way out of the first one's starting square.
+	snakes := newGridSnakes(bus, occupancy, nil,
+		[][]Location{
+			{NewLocation(0, 0)},
+			{NewLocation(2, 2), NewLocation(2, 1), NewLocation(2, 0)},
+		},
+		[]Direction{Left, Up},
+	)
+	a := snakes[0]
+	a.config.StartingPosition = NewLocation(2, 2)
+	a.config.RespawnDelay = 1
+	a.lives = 2
+
+	for step := 1; step <= 4; step++ {
+		moveTogether(BothDie, snakes, 1)
+		// the second snake's tail is in the way until the fourth step.
+		if want := step >= 4; a.OnBoard() != want {
+			t.Fatalf("after step %d OnBoard() = %v, want %v", step, a.OnBoard(), want)
+		}
+		if a.OnBoard() && occupancy.Has(1, a.head()) {
+			t.Fatalf("after step %d came back on top of the other snake", step)
+		}
+	}
+	if !a.head().Equal(NewLocation(2, 2)) {
+		t.Errorf("came back at %v, want (2, 2)", a.head())
+	}
+}
+
+func TestInvulnerableSnakesPassThrough(t *testing.T) {
+	edges := Edges{Right: 10, Top: 10}
+	tests := []struct {
+		name         string
+		invulnerable float64
+		wantDied     []int
+	}{
+		// a step wears a second off before the snakes move.
+		{name: "invulnerable", invulnerable: 2, wantDied: nil},
+		{name: "not invulnerable", invulnerable: 0, wantDied: []int{0, 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bus := events.NewBus()
+			var died []int
+			events.Subscribe(bus, func(e events.SnakeDied) {
+				died = append(died, e.Player)
+			})
+			// the first snake goes into the second's body, as the second
+			// goes into the first's.
+			snakes := newGridSnakes(bus, NewOccupancy(edges), nil,
+				[][]Location{
+					{NewLocation(2, 2), NewLocation(2, 1), NewLocation(2, 0)},
+					{NewLocation(3, 1), NewLocation(3, 2), NewLocation(3, 3)},
+				},
+				[]Direction{Right, Left},
+			)
+			snakes[0].invulnerable = test.invulnerable
+			moveTogether(BothDie, snakes, 1)
+
+			if len(died) != len(test.wantDied) {
+				t.Fatalf("players that died = %v, want %v", died, test.wantDied)
+			}
+			for i := range died {
+				if died[i] != test.wantDied[i] {
+					t.Fatalf("players that died = %v, want %v", died, test.wantDied)
+				}
+			}
+		})
+	}
+}
+
+func TestDifficultyPresets(t *testing.T) {
+	tests := []struct {
+		name string
+		want Difficulty
+	}{
+		{name: "easy", want: Easy},
+		{name: "Normal", want: Normal},
+		{name: "HARD", want: Hard},
+		{name: "insane", want: Insane},
+		{name: "unknown", want: Normal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := GetDifficulty(test.name)
+			if d != test.want {
+				t.Fatalf("GetDifficulty(%q) = %+v, want %+v", test.name, d, test.want)
+			}
+
+			if got := d.Level(0); got != 1 {
+				t.Errorf("Level(0) = %d, want 1", got)
+			}
+			if got := d.Level(d.PointsPerLevel - 1); got != 1 {
+				t.Errorf("Level(%d) = %d, want 1", d.PointsPerLevel-1, got)
+			}
+			if got := d.Level(d.PointsPerLevel); got != 2 {
+				t.Errorf("Level(%d) = %d, want 2", d.PointsPerLevel, got)
+			}
+			if got := d.Level(1000); got != d.MaxLevel {
+				t.Errorf("Level(1000) = %d, want %d", got, d.MaxLevel)
+			}
+
+			if got, want := d.Speed(10, 1), 10*d.SpeedScale; got != want {
+				t.Errorf("Speed(10, 1) = %v, want %v", got, want)
+			}
+			for level := 2; level <= d.MaxLevel; level++ {
+				if d.Speed(10, level) <= d.Speed(10, level-1) {
+					t.Errorf("Speed at level %d isn't faster than at level %d", level, level-1)
+				}
+			}
+			if got := d.Growth(4, 1); got != 4 {
+				t.Errorf("Growth(4, 1) = %d, want 4", got)
+			}
+			if got, want := d.Growth(4, 3), 4+2*d.GrowthPerLevel; got != want {
+				t.Errorf("Growth(4, 3) = %d, want %d", got, want)
+			}
+		})
+	}
+
+	// each preset is faster to begin with than the one before it.
+	presets := []Difficulty{Easy, Normal, Hard, Insane}
+	for i := 1; i < len(presets); i++ {
+		if presets[i].Speed(10, 1) <= presets[i-1].Speed(10, 1) {
+			t.Errorf("preset %d isn't faster than preset %d", i, i-1)
+		}
+	}
+}
+
+// everywhere is an item that's in every square, so a snake eats on every
+// step.
+type everywhere struct{}
+
+func (everywhere) At(_ Location) bool {
+	return true
+}
+
+func (everywhere) Reset(_ *list.List) {}
+
+func TestSnakeLevelsUp(t *testing.T) {
+	bus := events.NewBus()
+	var levels []int
+	events.Subscribe(bus, func(e events.LevelChanged) {
+		levels = append(levels, e.Level)
+	})
+	s := newGridSnakes(bus, NewOccupancy(Edges{Right: 20, Top: 1}), everywhere{},
+		[][]Location{{NewLocation(0, 0)}}, []Direction{Right})[0]
+	s.config.Difficulty = Hard
+	s.level = Hard.Level(0)
+
+	for i := 0; i < Hard.PointsPerLevel; i++ {
+		if s.Level() != 1 {
+			t.Fatalf("level %d after %d items, want 1", s.Level(), s.Score())
+		}
+		moveTogether(BothDie, []*Snake{s}, 1)
+	}
+
+	if s.Level() != 2 || len(levels) != 1 || levels[0] != 2 {
+		t.Fatalf("level %d after %d items with LevelChanged to %v, want 2", s.Level(), s.Score(), levels)
+	}
+	state := s.State()
+	if want := Hard.Speed(1, 2); state.Speed != want {
+		t.Errorf("speed = %v, want %v", state.Speed, want)
+	}
+	// each item eaten from now on grows the snake by one more square.
+	if want := Hard.Growth(2, 2); s.grow < want {
+		t.Errorf("growing by %d, want at least %d", s.grow, want)
+	}
+}
+
+func TestNewGameSpreadsSnakesOut(t *testing.T) {
+	for _, edges := range []Edges{{Right: 10, Top: 10}, {Right: 40, Top: 30}, {Left: -5, Right: 5, Bottom: -5, Top: 5}} {
+		for n := 1; n <= 8; n++ {
+			config := SnakeConfig{Movement: Grid, StartingFrames: 3, Lives: 1}
+			world := NewGame(events.NewBus(), edges, config, BothDie, n, 1)
+			state := world.State()
+			if len(state.Snakes) != n {
+				t.Fatalf("%v with %d snakes: got %d snakes", edges, n, len(state.Snakes))
+			}
+
+			seen := make(map[Location]int)
+			for i, s := range state.Snakes {
+				if len(s.Locations) != 1 {
+					t.Fatalf("%v with %d snakes: snake %d has %d pieces, want 1", edges, n, i, len(s.Locations))
+				}
+				head := Square(s.Locations[0])
+				if !edges.Contains(head) {
+					t.Errorf("%v with %d snakes: snake %d starts off the board at %v", edges, n, i, head)
+				}
+				if other, ok := seen[head]; ok {
+					t.Errorf("%v with %d snakes: snakes %d and %d both start at %v", edges, n, other, i, head)
+				}
+				seen[head] = i
+				if head.Equal(state.Item) {
+					t.Errorf("%v with %d snakes: snake %d starts on the item", edges, n, i)
+				}
+			}
+			for i, s := range world.Snakes() {
+				if s.Player() != i {
+					t.Errorf("%v with %d snakes: snake %d is player %d", edges, n, i, s.Player())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/kristinaspring/snake-go/events"
+)
+
+// sessionStats counts what has happened since the game started, based on the
+// game's events.
+type sessionStats struct {
+	lock   sync.Mutex
+	deaths map[events.Cause]int
+	eaten  int
+	rounds int
+}
+
+func newSessionStats(bus *events.Bus) *sessionStats {
+	s := &sessionStats{
+		deaths: make(map[events.Cause]int),
+	}
+	events.Subscribe(bus, s.snakeDied)
+	events.Subscribe(bus, s.itemEaten)
+	events.Subscribe(bus, s.roundOver)
+	return s
+}
+
+func (s *sessionStats) snakeDied(e events.SnakeDied) {
+	s.lock.Lock()
+	s.deaths[e.Cause]++
+	s.lock.Unlock()
+}
+
+func (s *sessionStats) itemEaten(_ events.ItemEaten) {
+	s.lock.Lock()
+	s.eaten++
+	s.lock.Unlock()
+}
+
+func (s *sessionStats) roundOver(_ events.RoundOver) {
+	s.lock.Lock()
+	s.rounds++
+	s.lock.Unlock()
+}
+
+func (s *sessionStats) String() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return fmt.Sprintf("rounds: %d, eaten: %d, deaths wall/self/snake: %d/%d/%d", s.rounds, s.eaten,
+		s.deaths[events.CauseWall], s.deaths[events.CauseSelf], s.deaths[events.CauseSnake])
+}
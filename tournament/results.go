@@ -0,0 +1,194 @@
+package tournament
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+const (
+	// InitialRating is every entrant's Elo rating before they've played.
+	InitialRating = 1500
+	// ratingK is how far a rating moves after each game.
+	ratingK = 32
+)
+
+// Results is how every entrant did in a tournament.
+type Results struct {
+	Standings []Standing `json:"standings"`
+	// Table has how each entrant did against each of the others.
+	Table []Pairing `json:"table"`
+	Games []Game    `json:"games"`
+}
+
+// Standing is how an entrant did overall.
+type Standing struct {
+	Name         string  `json:"name"`
+	Games        int     `json:"games"`
+	Wins         int     `json:"wins"`
+	Losses       int     `json:"losses"`
+	Draws        int     `json:"draws"`
+	AverageScore float64 `json:"averageScore"`
+	Rating       float64 `json:"rating"`
+
+	totalScore int
+}
+
+// Pairing is how an entrant did against one other entrant.
+type Pairing struct {
+	Name     string `json:"name"`
+	Opponent string `json:"opponent"`
+	Wins     int    `json:"wins"`
+	Losses   int    `json:"losses"`
+	Draws    int    `json:"draws"`
+}
+
+func newResults(entrants []Entrant) *Results {
+	r := &Results{
+		Standings: make([]Standing, len(entrants)),
+	}
+	for i, e := range entrants {
+		r.Standings[i] = Standing{Name: e.Name, Rating: InitialRating}
+	}
+	for i := range entrants {
+		for j := range entrants {
+			if i != j {
+				r.Table = append(r.Table, Pairing{Name: entrants[i].Name, Opponent: entrants[j].Name})
+			}
+		}
+	}
+	return r
+}
+
+// add counts a game, updating the entrants' ratings in the order the games
+// were played.
+func (r *Results) add(g Game) {
+	r.Games = append(r.Games, g)
+	a, b := &r.Standings[g.Entrants[0]], &r.Standings[g.Entrants[1]]
+
+	// how well the first entrant did: 1 for a win, 0 for a loss.
+	outcome := 0.5
+	switch g.Winner {
+	case 0:
+		outcome = 1
+		a.Wins++
+		b.Losses++
+	case 1:
+		outcome = 0
+		a.Losses++
+		b.Wins++
+	default:
+		a.Draws++
+		b.Draws++
+	}
+	r.pairing(g.Entrants[0], g.Entrants[1]).count(outcome)
+	r.pairing(g.Entrants[1], g.Entrants[0]).count(1 - outcome)
+
+	expected := 1 / (1 + math.Pow(10, (b.Rating-a.Rating)/400))
+	change := ratingK * (outcome - expected)
+	a.Rating += change
+	b.Rating -= change
+
+	for i, s := range []*Standing{a, b} {
+		s.Games++
+		s.totalScore += g.Scores[i]
+		s.AverageScore = float64(s.totalScore) / float64(s.Games)
+	}
+}
+
+// pairing returns how entrant i did against entrant j.
+func (r *Results) pairing(i int, j int) *Pairing {
+	// the table leaves out every entrant playing themselves.
+	n := len(r.Standings)
+	index := i*(n-1) + j
+	if j > i {
+		index--
+	}
+	return &r.Table[index]
+}
+
+func (p *Pairing) count(outcome float64) {
+	switch outcome {
+	case 1:
+		p.Wins++
+	case 0:
+		p.Losses++
+	default:
+		p.Draws++
+	}
+}
+
+// Ranked returns the standings from the highest rating to the lowest.
+func (r *Results) Ranked() []Standing {
+	ranked := append([]Standing(nil), r.Standings...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Rating > ranked[j].Rating
+	})
+	return ranked
+}
+
+// WriteJSON writes the results to w as JSON, with the standings ranked.
+func (r *Results) WriteJSON(w io.Writer) error {
+	ranked := *r
+	ranked.Standings = r.Ranked()
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(ranked)
+}
+
+// WriteCSV writes the ranked standings to w as CSV, with a header row.
+func (r *Results) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{
+		{"name", "games", "wins", "losses", "draws", "average_score", "rating"},
+	}
+	for _, s := range r.Ranked() {
+		rows = append(rows, []string{
+			s.Name,
+			strconv.Itoa(s.Games),
+			strconv.Itoa(s.Wins),
+			strconv.Itoa(s.Losses),
+			strconv.Itoa(s.Draws),
+			strconv.FormatFloat(s.AverageScore, 'f', 2, 64),
+			strconv.FormatFloat(s.Rating, 'f', 1, 64),
+		})
+	}
+	err := cw.WriteAll(rows)
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteTableCSV writes the win/loss table to w as CSV. There's a row for
+// each entrant and a column for each opponent, and each cell has the wins,
+// losses and draws of the row's entrant against the column's, like "3-1-0".
+func (r *Results) WriteTableCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"name"}
+	for _, s := range r.Standings {
+		header = append(header, s.Name)
+	}
+	rows := [][]string{header}
+	for i, s := range r.Standings {
+		row := []string{s.Name}
+		for j := range r.Standings {
+			cell := ""
+			if i != j {
+				p := r.pairing(i, j)
+				cell = fmt.Sprintf("%d-%d-%d", p.Wins, p.Losses, p.Draws)
+			}
+			row = append(row, cell)
+		}
+		rows = append(rows, row)
+	}
+	err := cw.WriteAll(rows)
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
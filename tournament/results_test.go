@@ -0,0 +1,104 @@
+package tournament
+
+import (
+	"math"
+	"testing"
+)
+
+func entrants(names ...string) []Entrant {
+	e := make([]Entrant, len(names))
+	for i, name := range names {
+		e[i] = Entrant{Name: name}
+	}
+	return e
+}
+
+func TestPairing(t *testing.T) {
+	r := newResults(entrants("a", "b", "c", "d"))
+	tests := []struct {
+		i, j     int
+		name     string
+		opponent string
+	}{
+		{i: 0, j: 1, name: "a", opponent: "b"},
+		{i: 0, j: 3, name: "a", opponent: "d"},
+		{i: 1, j: 0, name: "b", opponent: "a"},
+		{i: 1, j: 2, name: "b", opponent: "c"},
+		{i: 2, j: 1, name: "c", opponent: "b"},
+		{i: 2, j: 3, name: "c", opponent: "d"},
+		{i: 3, j: 0, name: "d", opponent: "a"},
+		{i: 3, j: 2, name: "d", opponent: "c"},
+	}
+	for _, test := range tests {
+		p := r.pairing(test.i, test.j)
+		if p.Name != test.name || p.Opponent != test.opponent {
+			t.Errorf("pairing(%d, %d) = %s against %s, want %s against %s", test.i, test.j, p.Name, p.Opponent, test.name, test.opponent)
+		}
+	}
+
+	// every pairing is somewhere in the table once.
+	seen := make(map[*Pairing]bool)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if i != j {
+				seen[r.pairing(i, j)] = true
+			}
+		}
+	}
+	if len(seen) != len(r.Table) {
+		t.Errorf("pairings cover %d of %d rows in the table", len(seen), len(r.Table))
+	}
+}
+
+func TestRatings(t *testing.T) {
+	tests := []struct {
+		name string
+		// the first entrant's rating before the game; the second's is
+		// always the initial rating.
+		rating float64
+		winner int
+		// the first entrant's rating after the game.
+		want                float64
+		wins, losses, draws int
+	}{
+		{name: "win", rating: InitialRating, winner: 0, want: 1516, wins: 1},
+		{name: "loss", rating: InitialRating, winner: 1, want: 1484, losses: 1},
+		{name: "draw", rating: InitialRating, winner: -1, want: 1500, draws: 1},
+		// beating someone rated 200 below gains much less.
+		{name: "expected win", rating: 1700, winner: 0, want: 1707.69, wins: 1},
+		{name: "upset", rating: 1700, winner: 1, want: 1675.69, losses: 1},
+		{name: "draw with someone worse", rating: 1700, winner: -1, want: 1691.69, draws: 1},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			r := newResults(entrants("a", "b"))
+			r.Standings[0].Rating = test.rating
+			r.add(Game{Entrants: [2]int{0, 1}, Scores: [2]int{3, 1}, Winner: test.winner})
+
+			a, b := r.Standings[0], r.Standings[1]
+			if math.Abs(a.Rating-test.want) > 0.01 {
+				t.Errorf("rating = %.2f, want %.2f", a.Rating, test.want)
+			}
+			// whatever one entrant gains, the other loses.
+			if sum := a.Rating + b.Rating; math.Abs(sum-test.rating-InitialRating) > 1e-9 {
+				t.Errorf("ratings add up to %v, want %v", sum, test.rating+InitialRating)
+			}
+			if a.Wins != test.wins || a.Losses != test.losses || a.Draws != test.draws {
+				t.Errorf("a went %d-%d-%d, want %d-%d-%d", a.Wins, a.Losses, a.Draws, test.wins, test.losses, test.draws)
+			}
+			if b.Wins != test.losses || b.Losses != test.wins || b.Draws != test.draws {
+				t.Errorf("b went %d-%d-%d, want %d-%d-%d", b.Wins, b.Losses, b.Draws, test.losses, test.wins, test.draws)
+			}
+			if p := r.pairing(0, 1); p.Wins != test.wins || p.Losses != test.losses || p.Draws != test.draws {
+				t.Errorf("a against b went %d-%d-%d, want %d-%d-%d", p.Wins, p.Losses, p.Draws, test.wins, test.losses, test.draws)
+			}
+			if p := r.pairing(1, 0); p.Wins != test.losses || p.Losses != test.wins || p.Draws != test.draws {
+				t.Errorf("b against a went %d-%d-%d, want %d-%d-%d", p.Wins, p.Losses, p.Draws, test.losses, test.wins, test.draws)
+			}
+			if a.Games != 1 || a.AverageScore != 3 || b.Games != 1 || b.AverageScore != 1 {
+				t.Errorf("standings = %+v", r.Standings)
+			}
+		})
+	}
+}
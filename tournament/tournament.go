@@ -0,0 +1,199 @@
+// Package tournament plays snakes against each other with no window, as fast
+// as they can go, and ranks them. Every entrant plays every other one, head
+// to head, the same number of games. Games are seeded, so a tournament
+// between entrants that don't depend on timing plays out the same way every
+// time it's run.
+package tournament
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kristinaspring/snake-go/events"
+	"github.com/kristinaspring/snake-go/gameloop"
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+const (
+	DefaultTickRate = time.Second / 60
+	DefaultMaxTime  = 5 * time.Minute
+)
+
+// Entrant is something that plays in a tournament.
+type Entrant struct {
+	Name string
+	// New makes what drives the entrant's snake in a new game, where it's
+	// the snake at index. If what it makes has a Wait method, it's called
+	// after every tick, and if it has a Close method, it's called once the
+	// game is over.
+	New func(index int) (sim.Controller, error)
+}
+
+// Setup describes the games in a tournament.
+type Setup struct {
+	Edges sim.Edges
+	// Snake is how every snake is set up, as sim.NewGame sets them up.
+	Snake sim.SnakeConfig
+	Rule  sim.Rule
+	// TickRate is how often the snakes move and are steered. It's
+	// DefaultTickRate if it's not positive.
+	TickRate time.Duration
+	// MaxTime is how long a game can go on for, in game time, before it's
+	// decided on score. It's DefaultMaxTime if it's not positive.
+	MaxTime time.Duration
+	// Games is how many games each pair of entrants plays. They swap sides
+	// every game.
+	Games int
+	// Seed is the seed for the first game of every pairing. Each game after
+	// that uses the next seed along.
+	Seed int64
+}
+
+// Game is how one game went.
+type Game struct {
+	// Entrants are the indexes of the entrants that played, in the order
+	// their snakes were.
+	Entrants [2]int
+	Seed     int64
+	Scores   [2]int
+	// Winner is the index in Entrants of who won, or -1 for a draw.
+	Winner int
+	// Seconds is how long the game went on for, in game time.
+	Seconds float64
+}
+
+// Run plays every entrant against every other one and returns how they did.
+func Run(setup Setup, entrants []Entrant) (*Results, error) {
+	if setup.TickRate <= 0 {
+		setup.TickRate = DefaultTickRate
+	}
+	if setup.MaxTime <= 0 {
+		setup.MaxTime = DefaultMaxTime
+	}
+	results := newResults(entrants)
+	for a := 0; a < len(entrants); a++ {
+		for b := a + 1; b < len(entrants); b++ {
+			for n := 0; n < setup.Games; n++ {
+				pair := [2]int{a, b}
+				if n%2 == 1 {
+					pair = [2]int{b, a}
+				}
+				game, err := play(setup, setup.Seed+int64(n), entrants, pair)
+				if err != nil {
+					return nil, fmt.Errorf("%s against %s: %w", entrants[pair[0]].Name, entrants[pair[1]].Name, err)
+				}
+				results.add(game)
+			}
+		}
+	}
+	return results, nil
+}
+
+// game runs a world through a gameloop, steering the snakes every tick.
+type game struct {
+	world *sim.World
+}
+
+func (g *game) Integrate(_ sim.WorldState, t float64, deltaT float64) sim.WorldState {
+	g.world.Steer(t)
+	g.world.Step(t, deltaT)
+	return g.world.State()
+}
+
+func (g *game) Render(_ sim.WorldState, _ sim.WorldState, _ float64, _ float64) {}
+
+// play plays one game between the entrants in pair.
+func play(setup Setup, seed int64, entrants []Entrant, pair [2]int) (Game, error) {
+	world := sim.NewGame(events.NewBus(), setup.Edges, setup.Snake, setup.Rule, len(pair), seed)
+	controllers := make([]sim.Controller, len(pair))
+	defer func() {
+		for _, controller := range controllers {
+			if closer, ok := controller.(interface{ Close() }); ok {
+				closer.Close()
+			}
+		}
+	}()
+	for i, e := range pair {
+		controller, err := entrants[e].New(i)
+		if err != nil {
+			return Game{}, err
+		}
+		controllers[i] = controller
+		world.SetController(i, controller)
+	}
+
+	loop := gameloop.NewLoop[sim.WorldState](&game{world: world}, setup.TickRate, world.State(), gameloop.NewManualClock(time.Unix(0, 0)))
+	maxTicks := uint64(setup.MaxTime / setup.TickRate)
+	for !decided(loop.State()) && loop.Ticks() < maxTicks {
+		loop.Step(1)
+		// bots answer in their own time, which games here don't wait for
+		// unless they're told to.
+		for _, controller := range controllers {
+			if waiter, ok := controller.(interface{ Wait() }); ok {
+				waiter.Wait()
+			}
+		}
+	}
+
+	state := loop.State()
+	g := Game{
+		Entrants: pair,
+		Seed:     seed,
+		Winner:   winner(state),
+		Seconds:  loop.Time(),
+	}
+	for i, s := range state.Snakes {
+		g.Scores[i] = s.Score
+	}
+	return g, nil
+}
+
+// decided reports whether the game is over, or only one snake has any lives
+// left.
+func decided(state sim.WorldState) bool {
+	if state.Over {
+		return true
+	}
+	alive := 0
+	for _, s := range state.Snakes {
+		if s.Lives > 0 {
+			alive++
+		}
+	}
+	return alive <= 1
+}
+
+// winner returns who won: whoever filled the board, or else the only snake
+// left, or else whoever scored the most. It returns -1 for a draw.
+func winner(state sim.WorldState) int {
+	if state.Won {
+		return state.Winner
+	}
+	alive := -1
+	for i, s := range state.Snakes {
+		if s.Lives > 0 {
+			if alive >= 0 {
+				alive = -1
+				break
+			}
+			alive = i
+		}
+	}
+	if alive >= 0 {
+		return alive
+	}
+
+	best, tied := -1, false
+	for i, s := range state.Snakes {
+		switch {
+		case best < 0 || s.Score > state.Snakes[best].Score:
+			best, tied = i, false
+		case s.Score == state.Snakes[best].Score:
+			tied = true
+		}
+	}
+	if tied {
+		return -1
+	}
+	return best
+}
@@ -0,0 +1,102 @@
+package tournament
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kristinaspring/snake-go/ai"
+	"github.com/kristinaspring/snake-go/sim"
+)
+
+func TestRun(t *testing.T) {
+	setup := Setup{
+		Edges:    sim.Edges{Right: 10, Top: 10},
+		Snake:    sim.SnakeConfig{Movement: sim.Grid, PixelsPerSec: 10, StartingFrames: 3, FramesToGrow: 1, Lives: 1},
+		Rule:     sim.BothDie,
+		TickRate: 100 * time.Millisecond,
+		MaxTime:  time.Minute,
+		Games:    2,
+		Seed:     1,
+	}
+	entrants := []Entrant{
+		{Name: "up", New: func(int) (sim.Controller, error) {
+			return sim.ControllerFunc(func(float64, sim.WorldState) sim.Direction {
+				return sim.Up
+			}), nil
+		}},
+		{Name: "greedy", New: func(index int) (sim.Controller, error) {
+			return ai.NewGreedy(index), nil
+		}},
+	}
+
+	results, err := Run(setup, entrants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// games are seeded and stepped on a manual clock, so they play out the
+	// same way every time.
+	again, err := Run(setup, entrants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(results, again) {
+		t.Errorf("second run = %+v, want %+v", again, results)
+	}
+
+	if len(results.Games) != 2 {
+		t.Fatalf("played %d games, want 2", len(results.Games))
+	}
+	// the entrants swap sides, and each game has the next seed.
+	for n, want := range [][2]int{{0, 1}, {1, 0}} {
+		if g := results.Games[n]; g.Entrants != want || g.Seed != int64(n+1) {
+			t.Errorf("game %d had entrants %v and seed %d, want %v and %d", n, g.Entrants, g.Seed, want, n+1)
+		}
+	}
+	ranked := results.Ranked()
+	if ranked[0].Name != "greedy" || ranked[0].Wins != 2 || ranked[1].Losses != 2 {
+		t.Fatalf("standings = %+v, want greedy to win both games", ranked)
+	}
+
+	var b bytes.Buffer
+	if err := results.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0][0] != "name" || rows[1][0] != "greedy" || rows[1][2] != "2" || rows[2][0] != "up" || rows[2][3] != "2" {
+		t.Errorf("CSV = %v", rows)
+	}
+
+	b.Reset()
+	if err := results.WriteTableCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	if want := "name,up,greedy\nup,,0-2-0\ngreedy,2-0-0,\n"; b.String() != want {
+		t.Errorf("table CSV = %q, want %q", b.String(), want)
+	}
+
+	b.Reset()
+	if err := results.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var written Results
+	if err := json.Unmarshal(b.Bytes(), &written); err != nil {
+		t.Fatal(err)
+	}
+	// the written standings are ranked, and leave out what's unexported.
+	for i := range ranked {
+		ranked[i].totalScore = 0
+	}
+	if !reflect.DeepEqual(written.Standings, ranked) {
+		t.Errorf("JSON standings = %+v, want %+v", written.Standings, ranked)
+	}
+	if !reflect.DeepEqual(written.Table, results.Table) || !reflect.DeepEqual(written.Games, results.Games) {
+		t.Errorf("JSON = %s", b.String())
+	}
+}